@@ -2,17 +2,16 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
+	"os"
 
 	"symbolic-execution-course/internal/ssa"
 )
 
-func main() {
-	fmt.Println("=== SSA Builder Demo ===")
-
-	// Пример исходного кода для анализа
-	source := `
+// Пример исходного кода для анализа
+const demoSource = `
 package main
 
 func testFunction(x int) int {
@@ -24,13 +23,52 @@ func testFunction(x int) int {
 }
 `
 
+func main() {
+	file := flag.String("file", "", "файл с исходным кодом Go (по умолчанию - встроенный пример)")
+	funcName := flag.String("func", "testFunction", "имя анализируемой функции")
+	format := flag.String("format", "text", "формат вывода: text, dot или json")
+	importerName := flag.String("importer", "auto", "импорт пакетов: auto, gc, source или packages")
+	flag.Parse()
+
+	importer, err := ssa.ParseImporterMode(*importerName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	source := demoSource
+	if *file != "" {
+		content, err := os.ReadFile(*file)
+		if err != nil {
+			log.Fatalf("Ошибка чтения файла: %v", err)
+		}
+		source = string(content)
+	}
+
 	// Создаём builder для SSA
 	builder := ssa.NewBuilder()
+	builder.UseImporter(importer)
 
 	// Строим SSA из исходного кода
-	graph, err := builder.ParseAndBuildSSA(source, "testFunction")
+	graph, err := builder.ParseAndBuildSSA(source, *funcName)
 	if err != nil {
 		log.Fatalf("Ошибка построения SSA: %v", err)
 	}
-	fmt.Printf("CFG построен для функции с %d блоками\n", len(graph.Blocks))
+
+	switch *format {
+	case "text":
+		fmt.Println("=== SSA Builder Demo ===")
+		fmt.Printf("CFG построен для функции с %d блоками\n", len(graph.Blocks))
+		if _, err := graph.WriteTo(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+	case "dot":
+		err = ssa.WriteDOT(os.Stdout, graph)
+	case "json":
+		err = ssa.WriteJSON(os.Stdout, graph)
+	default:
+		log.Fatalf("Неизвестный формат вывода: %s", *format)
+	}
+	if err != nil {
+		log.Fatalf("Ошибка экспорта: %v", err)
+	}
 }
@@ -1,42 +1,86 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"log"
 	"os"
+	"regexp"
+	"strings"
 	"symbolic-execution-course/internal"
+	"symbolic-execution-course/internal/ssa"
 )
 
 func main() {
-	source_bytes, _ := os.ReadFile("./examples/test_functions.go")
-
-	source := string(source_bytes)
-	test_functions := []string{
-		"test1",
-		"test2",
-		"testArithmetic",
-		"testUnary",
-		"testComparisons",
-		"testLogicalOps",
-		"testWhileLoop",
-		"testForLoop",
-		"testInfiniteLoopBreak",
-		"testLoopWithConcreteBoundAndSymbolicBranching",
-		"testLoopWithSymbolicBoundAndSymbolicBranching",
-		"testComplexConditions",
-		"testNestedIf",
-		"testBitwise",
-		"testCombined",
-		"testEdgeCases",
-		"testMultipleReturns",
-		"testSimpleSum",
+	file := flag.String("file", "./examples/test_functions.go", "файл с анализируемыми функциями")
+	pkg := flag.String("pkg", "", "шаблоны пакетов через пробел (например, ./...); если заданы, -file не используется")
+	dir := flag.String("dir", ".", "каталог, относительно которого разрешаются шаблоны -pkg")
+	run := flag.String("run", "", "регулярное выражение для имён анализируемых функций")
+	exported := flag.Bool("exported", false, "анализировать только экспортируемые функции")
+	kindName := flag.String("kind", "all", "вид функций: all, func, method или closure")
+	list := flag.Bool("list", false, "только перечислить найденные функции")
+	quiet := flag.Bool("quiet", false, "не печатать пути исполнения, только итоговую таблицу")
+	blocks := flag.Int("blocks", internal.DefaultBlockLimit, "наибольшее число исполняемых базовых блоков на функцию; 0 снимает ограничение")
+	flag.Parse()
+
+	filter := ssa.FunctionFilter{ExportedOnly: *exported}
+
+	kind, err := ssa.ParseFunctionKind(*kindName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	filter.Kind = kind
+
+	if *run != "" {
+		filter.Pattern, err = regexp.Compile(*run)
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
 
-	for _, fun := range test_functions {
-		fmt.Printf("=== %s ===\n", fun)
-		result := internal.Analyse(string(source), fun)
-		for _, interpreter := range result {
-			fmt.Println(interpreter)
+	// Файл или пакеты разбираются и переводятся в SSA один раз для всех функций
+	session := internal.NewSession()
+	session.BlockLimit = *blocks
+
+	var program *ssa.Program
+	if *pkg != "" {
+		program, err = ssa.NewBuilder().LoadAndBuildSSA(*dir, strings.Fields(*pkg)...)
+	} else {
+		var source_bytes []byte
+		source_bytes, err = os.ReadFile(*file)
+		if err != nil {
+			log.Fatal(err)
 		}
+		program, err = session.Program(string(source_bytes))
+	}
+	if err != nil {
+		log.Fatal(err)
 	}
 
+	functions := program.Functions(filter)
+	if *list {
+		for _, fn := range functions {
+			fmt.Println(ssa.FunctionName(fn))
+		}
+		return
+	}
+
+	summaries := session.AnalyseAll(functions)
+
+	if !*quiet {
+		for _, summary := range summaries {
+			fmt.Printf("=== %s ===\n", summary.Name)
+			for _, interpreter := range summary.Results {
+				fmt.Print(interpreter.Report())
+				fmt.Println()
+			}
+			if summary.Err != nil {
+				fmt.Printf("error: %v\n", summary.Err)
+			}
+		}
+	}
+
+	if err := internal.WriteSummary(os.Stdout, summaries); err != nil {
+		log.Fatal(err)
+	}
 }
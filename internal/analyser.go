@@ -1,6 +1,9 @@
 package internal
 
 import (
+	"errors"
+	"go/token"
+	"go/types"
 	"symbolic-execution-course/internal/memory"
 	issa "symbolic-execution-course/internal/ssa"
 	"symbolic-execution-course/internal/symbolic"
@@ -15,27 +18,97 @@ type Analyser struct {
 	PathSelector PathSelector
 	Results      []Interpreter
 	Z3Translator *translator.Z3Translator
+	Loops        map[*ssa.Function]*issa.LoopInfo
+	// LoopBound - наибольшее число обратных переходов в каждый цикл на одном пути,
+	// пути с большим числом итераций отбрасываются; 0 снимает ограничение
+	LoopBound int
+	// Truncated сообщает, что анализ остановлен по Session.BlockLimit и не все пути исследованы
+	Truncated  bool
+	SourceMaps map[*ssa.Function]*issa.SourceMap
+	Inputs     []Input
+	Visited    map[*ssa.BasicBlock]bool
+	// Expressions интернирует условия путей и результаты, чтобы состояния разделяли общие подвыражения
+	Expressions *symbolic.Factory
 }
 
-func createAnalyser(source string, functionName string, selector PathSelector) *Analyser {
-	builder := issa.NewBuilder()
+// Input - входное значение анализируемой функции: параметр или захваченная переменная
+type Input struct {
+	// Name - идентификатор Go, он же имя символьной переменной
+	Name string
+	// Pos - позиция объявления в исходном коде
+	Pos token.Position
+}
 
-	graph, err := builder.ParseAndBuildSSA(source, functionName)
-	if err != nil {
-		panic("ssa parsing failed")
+// LoopInfo возвращает структуру циклов функции, вычисляя её при первом обращении
+func (analyser *Analyser) LoopInfo(fn *ssa.Function) *issa.LoopInfo {
+	info, ok := analyser.Loops[fn]
+	if !ok {
+		info = issa.AnalyseLoops(fn)
+		analyser.Loops[fn] = info
+	}
+	return info
+}
+
+// SourceMap возвращает отображение функции на исходный код, строя его при первом обращении
+func (analyser *Analyser) SourceMap(fn *ssa.Function) *issa.SourceMap {
+	sourceMap, ok := analyser.SourceMaps[fn]
+	if !ok {
+		sourceMap = issa.NewSourceMap(fn)
+		analyser.SourceMaps[fn] = sourceMap
+	}
+	return sourceMap
+}
+
+func createAnalyser(graph *ssa.Function, selector PathSelector, zt *translator.Z3Translator) (*Analyser, error) {
+	if err := checkSupported(graph); err != nil {
+		return nil, err
 	}
 
-	zt := translator.NewZ3Translator()
+	heap := memory.NewSymbolicMemory()
 
 	frame := CallStackFrame{
-		Function:     graph,
-		LocalMemory:  map[ssa.Value]symbolic.SymbolicExpression{},
-		ReturnValue:  nil,
-		CurrentBlock: 0,
+		Function:       graph,
+		LocalMemory:    map[ssa.Value]symbolic.SymbolicExpression{},
+		FieldAddresses: map[ssa.Value]FieldAddress{},
+		ReturnValue:    nil,
+		CurrentBlock:   0,
 	}
 
+	var inputs []Input
+
+	// Для методов получатель входит в graph.Params и становится символьным наравне с остальными параметрами
 	for _, param := range graph.Params {
-		frame.LocalMemory[param] = symbolic.NewSymbolicVariable(param.Name(), ConvertType(param.Type()))
+		value, err := symbolicParameter(heap, param.Name(), param.Type())
+		if err != nil {
+			return nil, withPosition(err, graph, param.Pos())
+		}
+		frame.LocalMemory[param] = value
+		inputs = append(inputs, Input{Name: param.Name(), Pos: graph.Prog.Fset.Position(param.Pos())})
+	}
+	// Захваченные переменные анонимной функции также считаются входными данными.
+	// go/ssa передаёт их по указателю, поэтому переменная базового типа размещается
+	// в памяти как объект из одного поля, адресом которого становится freeVar.
+	for _, freeVar := range graph.FreeVars {
+		if ptr, ok := freeVar.Type().Underlying().(*types.Pointer); ok {
+			if _, ok := ptr.Elem().Underlying().(*types.Struct); !ok {
+				value, err := symbolicParameter(heap, freeVar.Name(), ptr.Elem())
+				if err != nil {
+					return nil, withPosition(err, graph, freeVar.Pos())
+				}
+				cell := heap.Allocate(symbolic.ObjectType)
+				heap.AssignField(cell, 0, value)
+				frame.FieldAddresses[freeVar] = FieldAddress{Object: cell, Field: 0}
+				inputs = append(inputs, Input{Name: freeVar.Name(), Pos: graph.Prog.Fset.Position(freeVar.Pos())})
+				continue
+			}
+		}
+
+		value, err := symbolicParameter(heap, freeVar.Name(), freeVar.Type())
+		if err != nil {
+			return nil, withPosition(err, graph, freeVar.Pos())
+		}
+		frame.LocalMemory[freeVar] = value
+		inputs = append(inputs, Input{Name: freeVar.Name(), Pos: graph.Prog.Fset.Position(freeVar.Pos())})
 	}
 
 	res := &Analyser{
@@ -43,6 +116,11 @@ func createAnalyser(source string, functionName string, selector PathSelector) *
 		PathSelector: selector,
 		Results:      []Interpreter{},
 		Z3Translator: zt,
+		Loops:        map[*ssa.Function]*issa.LoopInfo{},
+		SourceMaps:   map[*ssa.Function]*issa.SourceMap{},
+		Inputs:       inputs,
+		Visited:      map[*ssa.BasicBlock]bool{},
+		Expressions:  symbolic.NewFactory(),
 	}
 
 	start := Interpreter{
@@ -50,7 +128,7 @@ func createAnalyser(source string, functionName string, selector PathSelector) *
 			frame,
 		},
 		PathCondition: symbolic.NewBoolConstant(true),
-		Heap:          memory.NewSymbolicMemory(),
+		Heap:          heap,
 		Analyser:      res,
 	}
 
@@ -63,24 +141,100 @@ func createAnalyser(source string, functionName string, selector PathSelector) *
 
 	res.StatesQueue = queue
 
-	return res
+	return res, nil
+}
+
+// symbolicParameter создаёт символьное входное значение параметра.
+// Структуры и указатели на структуры размещаются в символьной памяти,
+// а их поля становятся символьными переменными вида "p.Age"
+func symbolicParameter(heap memory.Memory, name string, tpe types.Type) (symbolic.SymbolicExpression, error) {
+	if ptr, ok := tpe.Underlying().(*types.Pointer); ok {
+		if _, ok := ptr.Elem().Underlying().(*types.Struct); ok {
+			return symbolicObject(heap, name, ptr.Elem()), nil
+		}
+	}
+	if _, ok := tpe.Underlying().(*types.Struct); ok {
+		return symbolicObject(heap, name, tpe), nil
+	}
+
+	exprType, err := ConvertType(tpe)
+	if err != nil {
+		return nil, err
+	}
+	return symbolic.NewSymbolicVariable(name, exprType), nil
+}
+
+// symbolicObject размещает в памяти объект структурного типа с символьными полями.
+// Вложенные структуры размещаются рекурсивно, поля неподдерживаемых типов остаются неопределёнными.
+func symbolicObject(heap memory.Memory, name string, tpe types.Type) *symbolic.Ref {
+	st := tpe.Underlying().(*types.Struct)
+	ref := heap.Allocate(symbolic.ObjectType)
+
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		fieldName := name + "." + field.Name()
+
+		if _, ok := field.Type().Underlying().(*types.Struct); ok {
+			heap.AssignField(ref, i, symbolicObject(heap, fieldName, field.Type()))
+		} else if exprType, ok := convertType(field.Type()); ok {
+			heap.AssignField(ref, i, symbolic.NewSymbolicVariable(fieldName, exprType))
+		}
+	}
+
+	return ref
 }
 
-func Analyse(source string, functionName string) []Interpreter {
-	analyser := createAnalyser(source, functionName, &RandomPathSelector{})
-
-	i := 0
-	for i < 10 && analyser.StatesQueue.Len() > 0 {
-		state := analyser.StatesQueue.Pop().(*Item).value
-		new_states := state.interpretCurrentBlock()
-		for _, new_state := range new_states {
-			analyser.StatesQueue.Push(&Item{
-				value:    new_state,
-				priority: analyser.PathSelector.CalculatePriority(new_state),
-			})
+// Coverage возвращает долю базовых блоков функции, посещённых хотя бы на одном пути
+func (analyser *Analyser) Coverage(fn *ssa.Function) float64 {
+	if len(fn.Blocks) == 0 {
+		return 0
+	}
+
+	visited := 0
+	for _, block := range fn.Blocks {
+		if analyser.Visited[block] {
+			visited++
 		}
-		i++
 	}
+	return float64(visited) / float64(len(fn.Blocks))
+}
+
+// withPosition дополняет ошибку о неподдерживаемой конструкции позицией в исходном коде
+func withPosition(err error, graph *ssa.Function, pos token.Pos) error {
+	var unsupported *issa.UnsupportedError
+	if errors.As(err, &unsupported) && !unsupported.Pos.IsValid() {
+		unsupported.Pos = graph.Prog.Fset.Position(pos)
+	}
+	return err
+}
+
+// Analyse строит SSA по исходному коду одного файла и анализирует функцию functionName.
+// Для анализа нескольких функций одного файла следует использовать Session.
+func Analyse(source string, functionName string) ([]Interpreter, error) {
+	return NewSession().Analyse(source, functionName)
+}
+
+// AnalysePackages загружает пакеты по шаблонам относительно dir и анализирует
+// функцию functionName из любого загруженного пакета
+func AnalysePackages(dir string, patterns []string, functionName string) ([]Interpreter, error) {
+	builder := issa.NewBuilder()
+	builder.Instantiate(functionName)
+
+	program, err := builder.LoadAndBuildSSA(dir, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := program.Function(functionName)
+	if err != nil {
+		return nil, err
+	}
+
+	return AnalyseFunction(graph)
+}
 
-	return analyser.Results
+// AnalyseFunction запускает символьное исполнение уже построенной SSA функции
+// с собственным Z3 транслятором
+func AnalyseFunction(graph *ssa.Function) ([]Interpreter, error) {
+	return NewSession().AnalyseFunction(graph)
 }
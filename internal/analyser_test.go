@@ -0,0 +1,342 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"symbolic-execution-course/internal/memory"
+	issa "symbolic-execution-course/internal/ssa"
+	"symbolic-execution-course/internal/symbolic"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// paths описывает завершённые пути в виде "условие => [результаты]" в порядке сортировки
+func paths(results []Interpreter) []string {
+	res := make([]string, 0, len(results))
+	for _, result := range results {
+		if result.Panic != nil {
+			res = append(res, fmt.Sprintf("%s => panic", result.PathCondition))
+			continue
+		}
+		res = append(res, fmt.Sprintf("%s => %v", result.PathCondition, result.frame().ReturnValue))
+	}
+	slices.Sort(res)
+	return res
+}
+
+// analyserTest - анализ функции function исходного кода source с ожидаемыми путями
+type analyserTest struct {
+	name     string
+	source   string
+	function string
+	expected []string
+}
+
+func runAnalyserTests(t *testing.T, tests []analyserTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := Analyse(tt.source, tt.function)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := paths(results); !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAnalyseEntryPoints(t *testing.T) {
+	const source = `package main
+
+type P struct {
+	Name string
+	Age  int
+}
+
+func (p P) Adult() bool {
+	return p.Age >= 18
+}
+
+func (p *P) Birthday() int {
+	p.Age = p.Age + 1
+	return p.Age
+}
+
+func copyStruct(p P) int {
+	q := p
+	q.Age = 5
+	return p.Age
+}
+
+func copyBack(p P) int {
+	q := p
+	q.Age = 5
+	p = q
+	return p.Age
+}
+
+func counter(n int) func() int {
+	return func() int {
+		n++
+		return n
+	}
+}
+
+func outer(limit int) func(int) bool {
+	return func(x int) bool {
+		return x > limit
+	}
+}
+`
+
+	runAnalyserTests(t, []analyserTest{
+		{"value receiver", source, "P.Adult", []string{"true => [(p.Age >= 18)]"}},
+		{"pointer receiver", source, "(*P).Birthday", []string{"true => [(p.Age + 1)]"}},
+		{"struct copy", source, "copyStruct", []string{"true => [p.Age]"}},
+		{"struct copy back", source, "copyBack", []string{"true => [5]"}},
+		{"closure", source, "outer$1", []string{"true => [(limit < x)]"}},
+		{"captured assignment", source, "counter$1", []string{"true => [(n + 1)]"}},
+	})
+}
+
+func TestAnalyseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		function string
+		check    func(err error) bool
+		line     int
+	}{
+		{"parse error", "package main\n\nfunc f( int {\n}\n", "f",
+			func(err error) bool { var e *issa.ParseError; return errors.As(err, &e) }, 3},
+		{"type check error", "package main\n\nfunc f() int {\n\treturn \"a\"\n}\n", "f",
+			func(err error) bool { var e *issa.TypeCheckError; return errors.As(err, &e) }, 4},
+		{"missing function", "package main\n\nfunc f() {}\n", "g",
+			func(err error) bool { var e *issa.FunctionNotFoundError; return errors.As(err, &e) }, 0},
+		{"unsupported parameter", "package main\n\nfunc f(\n\tm map[int]int) int {\n\treturn 0\n}\n", "f",
+			func(err error) bool { var e *issa.UnsupportedError; return errors.As(err, &e) }, 4},
+		{"unsupported instruction", "package main\n\nfunc f(x int) int {\n\ts := []int{x}\n\treturn s[0]\n}\n", "f",
+			func(err error) bool { var e *issa.UnsupportedError; return errors.As(err, &e) }, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Analyse(tt.source, tt.function)
+			if err == nil || !tt.check(err) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if tt.line > 0 && !strings.Contains(err.Error(), fmt.Sprintf(":%d:", tt.line)) {
+				t.Errorf("expected position at line %d, got %v", tt.line, err)
+			}
+		})
+	}
+}
+
+func TestAnalyseLoopBound(t *testing.T) {
+	const source = `package main
+
+func count(n int) int {
+	i := 0
+	for i < n {
+		i++
+	}
+	return i
+}
+`
+
+	tests := []struct {
+		bound    int
+		expected []string
+	}{
+		{1, []string{"((n > 0) && (n <= 1)) => [1]", "(n <= 0) => [0]"}},
+		{2, []string{"((n > 0) && (n <= 1)) => [1]", "((n > 0) && (n > 1) && (n <= 2)) => [2]", "(n <= 0) => [0]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.bound), func(t *testing.T) {
+			session := NewSession()
+			session.LoopBound = tt.bound
+			results, err := session.Analyse(source, "count")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := paths(results); !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAnalyseSizedIntegers(t *testing.T) {
+	const source = `package main
+
+func wrap(x uint8) uint8 {
+	return x + 200
+}
+
+func half(x int8, y uint16) (int8, uint16) {
+	return x / 2, y >> 3
+}
+
+func neg(x uint32) uint32 {
+	return -x
+}
+
+func compare(x uint64) bool {
+	if x < 10 {
+		return true
+	}
+	return false
+}
+
+func shift(x int16, s uint) int16 {
+	return x << s
+}
+
+func runes(r rune, b byte) bool {
+	return r == 'a' && b == 0xff
+}
+`
+
+	runAnalyserTests(t, []analyserTest{
+		{"wraparound", source, "wrap", []string{"true => [(x + 200)]"}},
+		{"division and shift", source, "half", []string{"true => [(x / 2) (y >> uint(3))]"}},
+		{"unsigned negation", source, "neg", []string{"true => [(x * 4294967295)]"}},
+		{"unsigned comparison", source, "compare", []string{"(x < 10) => [true]", "(x >= 10) => [false]"}},
+		{"shift by unsigned", source, "shift", []string{"true => [(x << s)]"}},
+		{"rune and byte", source, "runes", []string{"(r != 97) => [false]", "(r == 97) => [(b == 255)]"}},
+	})
+
+	types := map[string][]symbolic.ExpressionType{
+		"wrap": {symbolic.Uint8Type},
+		"half": {symbolic.Int8Type, symbolic.Uint16Type},
+		"neg":  {symbolic.Uint32Type},
+	}
+	for name, expected := range types {
+		results, err := Analyse(source, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, value := range results[0].frame().ReturnValue {
+			if value.Type() != expected[i] {
+				t.Errorf("%s: expected result %d of type %s, got %s", name, i, expected[i], value.Type())
+			}
+		}
+	}
+}
+
+func TestAnalyseStrings(t *testing.T) {
+	const source = `package main
+
+import "strings"
+
+func route(path string) string {
+	if strings.HasPrefix(path, "/api") {
+		return "api"
+	}
+	return path + "/"
+}
+
+func first(s string) byte {
+	return s[0]
+}
+
+func tail(s string, i int) string {
+	return s[i:]
+}
+
+func size(b []byte) int {
+	return len(b) + strings.Index(string(b), "x")
+}
+
+func bytes(s string) []byte {
+	return []byte(s)[1:2]
+}
+
+func less(a, b string) bool {
+	return a < b || strings.Contains(a, b)
+}
+`
+
+	runAnalyserTests(t, []analyserTest{
+		{"prefix", source, "route", []string{
+			`!hasPrefix(path, "/api") => [(path + "/")]`,
+			`hasPrefix(path, "/api") => ["api"]`,
+		}},
+		{"index", source, "first", []string{"true => [at(s, 0)]"}},
+		{"slice", source, "tail", []string{"true => [slice(s, i, len(s))]"}},
+		{"length", source, "size", []string{`true => [(index(string(b), "x") + len(b))]`}},
+		{"conversion", source, "bytes", []string{"true => [slice(bytes(s), 1, 2)]"}},
+		{"ordering", source, "less", []string{"(a < b) => [true]", "(a >= b) => [contains(a, b)]"}},
+	})
+}
+
+func TestAnalyseConversions(t *testing.T) {
+	const source = `package main
+
+func widen(x int8) int32 {
+	return int32(x) + 1
+}
+
+func narrow(n int) byte {
+	return byte(n)
+}
+
+func toFloat(x uint16) float64 {
+	return float64(x) / 2
+}
+
+func truncate(f float32) int64 {
+	return int64(f)
+}
+
+type Celsius float64
+
+func named(c Celsius) float64 {
+	return float64(c)
+}
+`
+
+	runAnalyserTests(t, []analyserTest{
+		{"widening", source, "widen", []string{"true => [(int32(x) + 1)]"}},
+		{"narrowing", source, "narrow", []string{"true => [uint8(n)]"}},
+		{"int to float", source, "toFloat", []string{"true => [(float(x) / 2)]"}},
+		{"float to int", source, "truncate", []string{"true => [int64(f)]"}},
+		{"named type", source, "named", []string{"true => [c]"}},
+	})
+}
+
+func TestForkCopiesState(t *testing.T) {
+	heap := memory.NewSymbolicMemory()
+	object := heap.Allocate(symbolic.ObjectType)
+	heap.AssignField(object, 0, symbolic.NewIntConstant(1))
+
+	original := &Interpreter{
+		CallStack: []CallStackFrame{{LoopIterations: map[*ssa.BasicBlock]int{}}},
+		Heap:      heap,
+	}
+	fork := original.fork()
+	fork.Heap.AssignField(object, 0, symbolic.NewIntConstant(2))
+	fork.Heap.Allocate(symbolic.ObjectType)
+	fork.frame().LoopIterations[nil] = 1
+
+	if value := original.Heap.GetFieldValue(object, 0); !value.Equal(symbolic.NewIntConstant(1)) {
+		t.Errorf("write in a fork is visible in the original state: %s", value)
+	}
+	if value := fork.Heap.GetFieldValue(object, 0); !value.Equal(symbolic.NewIntConstant(2)) {
+		t.Errorf("expected the fork to see its own write, got %s", value)
+	}
+	if len(original.frame().LoopIterations) != 0 {
+		t.Error("loop counters of a fork are shared with the original state")
+	}
+	// Выделения в копии не сдвигают счётчик адресов исходной памяти
+	if ref := original.Heap.Allocate(symbolic.ObjectType); ref.Ptr != 2 {
+		t.Errorf("expected the original allocation counter to be unaffected, got %d", ref.Ptr)
+	}
+}
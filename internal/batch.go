@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	issa "symbolic-execution-course/internal/ssa"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// FunctionSummary - итог анализа одной функции в пакетном режиме
+type FunctionSummary struct {
+	// Name - имя функции в форме issa.FunctionName
+	Name     string
+	Function *ssa.Function
+	// Results - завершённые пути исполнения
+	Results []Interpreter
+	// Paths - число завершённых путей
+	Paths int
+	// Errors - число путей, завершившихся паникой
+	Errors int
+	// Coverage - доля посещённых базовых блоков
+	Coverage float64
+	Duration time.Duration
+	// Truncated - анализ остановлен по Session.BlockLimit, и не все пути исследованы
+	Truncated bool
+	// Err - ошибка, из-за которой функцию не удалось проанализировать полностью;
+	// завершённые до неё пути всё равно входят в Results
+	Err error
+	// analysed - анализатор был создан, и счётчики путей и покрытия заполнены
+	analysed bool
+}
+
+// Status возвращает состояние анализа: "complete", "truncated" или "failed: <ошибка>"
+func (summary FunctionSummary) Status() string {
+	switch {
+	case summary.Err != nil && summary.Truncated:
+		return fmt.Sprintf("truncated, failed: %v", summary.Err)
+	case summary.Err != nil:
+		return fmt.Sprintf("failed: %v", summary.Err)
+	case summary.Truncated:
+		return "truncated"
+	}
+	return "complete"
+}
+
+// AnalyseAll анализирует функции по очереди. Ошибка анализа одной функции
+// сохраняется в её итоге и не прерывает анализ остальных.
+func (session *Session) AnalyseAll(functions []*ssa.Function) []FunctionSummary {
+	res := make([]FunctionSummary, 0, len(functions))
+	for _, fn := range functions {
+		res = append(res, session.summarise(fn))
+	}
+	return res
+}
+
+func (session *Session) summarise(fn *ssa.Function) FunctionSummary {
+	summary := FunctionSummary{
+		Name:     issa.FunctionName(fn),
+		Function: fn,
+	}
+
+	start := time.Now()
+	analyser, err := session.run(fn)
+	summary.Duration = time.Since(start)
+	summary.Err = err
+	if analyser == nil {
+		return summary
+	}
+
+	summary.analysed = true
+	summary.Truncated = analyser.Truncated
+	summary.Results = analyser.Results
+	summary.Paths = len(analyser.Results)
+	for _, result := range analyser.Results {
+		if result.Panic != nil {
+			summary.Errors++
+		}
+	}
+	summary.Coverage = analyser.Coverage(fn)
+	return summary
+}
+
+// WriteSummary печатает итоги пакетного анализа таблицей. Для неполного анализа
+// (STATUS "truncated" или "failed") PATHS, ERRORS и COVERAGE относятся только к исследованным путям.
+func WriteSummary(w io.Writer, summaries []FunctionSummary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FUNCTION\tPATHS\tERRORS\tCOVERAGE\tTIME\tSTATUS")
+
+	var total time.Duration
+	incomplete := 0
+	for _, summary := range summaries {
+		total += summary.Duration
+		if summary.Err != nil || summary.Truncated {
+			incomplete++
+		}
+		if !summary.analysed {
+			fmt.Fprintf(tw, "%s\t-\t-\t-\t%s\t%s\n", summary.Name, summary.Duration.Round(time.Microsecond), summary.Status())
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.0f%%\t%s\t%s\n",
+			summary.Name, summary.Paths, summary.Errors, summary.Coverage*100, summary.Duration.Round(time.Microsecond), summary.Status())
+	}
+
+	fmt.Fprintf(tw, "total: %d functions\t\t\t\t%s\t%d incomplete\n", len(summaries), total.Round(time.Microsecond), incomplete)
+	return tw.Flush()
+}
@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"bytes"
+	"regexp"
+	issa "symbolic-execution-course/internal/ssa"
+	"testing"
+)
+
+func TestAnalyseAll(t *testing.T) {
+	const source = `package main
+
+func sign(x int) int {
+	if x > 0 {
+		return 1
+	}
+	return 0
+}
+
+func check(x int) int {
+	if x < 0 {
+		panic("negative")
+	}
+	return x
+}
+
+func unsupported(m map[int]int) int {
+	return 0
+}
+
+type P struct {
+	M map[int]int
+}
+
+func partial(x int, p P) int {
+	if x > 0 {
+		return 1
+	}
+	_ = p.M
+	return 0
+}
+
+func loop(n int) int {
+	s := 0
+	for i := 0; i < n; i++ {
+		s += i
+	}
+	return s
+}
+`
+
+	session := NewSession()
+	program, err := session.Program(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Анализ loop прерывается до исследования всех путей
+	session.BlockLimit = 8
+	summaries := session.AnalyseAll(program.Functions(issa.FunctionFilter{}))
+	if len(summaries) != 5 {
+		t.Fatalf("expected 5 summaries, got %d", len(summaries))
+	}
+
+	expected := []struct {
+		name              string
+		paths, errors     int
+		truncated, failed bool
+	}{
+		{"sign", 2, 0, false, false},
+		{"check", 2, 1, false, false},
+		{"unsupported", 0, 0, false, true},
+		// Путь x > 0 сохраняется, хотя второй путь прерван паникой интерпретатора
+		{"partial", 1, 0, false, true},
+		{"loop", -1, 0, true, false},
+	}
+	for i, tt := range expected {
+		summary := summaries[i]
+		if summary.Name != tt.name || (tt.paths >= 0 && summary.Paths != tt.paths) || summary.Errors != tt.errors ||
+			summary.Truncated != tt.truncated || (summary.Err != nil) != tt.failed {
+			t.Errorf("unexpected summary %+v, expected %+v", summary, tt)
+		}
+		if !tt.failed && !tt.truncated && summary.Coverage != 1 {
+			t.Errorf("%s: expected full coverage, got %v", tt.name, summary.Coverage)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSummary(&buf, summaries); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pattern := range []string{
+		`(?m)^FUNCTION +PATHS +ERRORS +COVERAGE +TIME +STATUS$`,
+		`(?m)^sign +2 +0 +100% +\S+ +complete$`,
+		`(?m)^check +2 +1 +100% +\S+ +complete$`,
+		`(?m)^unsupported +- +- +- +\S+ +failed: .*unsupported construct: type map\[int\]int$`,
+		`(?m)^partial +1 +0 +\d+% +\S+ +failed: .*undefined object field$`,
+		`(?m)^loop +\d+ +0 +\d+% +\S+ +truncated$`,
+		`(?m)^total: 5 functions +\S+ +3 incomplete$`,
+	} {
+		if !regexp.MustCompile(pattern).Match(buf.Bytes()) {
+			t.Errorf("summary does not match %s:\n%s", pattern, buf.String())
+		}
+	}
+}
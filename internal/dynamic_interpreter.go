@@ -5,11 +5,12 @@ import (
 	"go/constant"
 	"go/token"
 	"go/types"
+	"maps"
 	"slices"
 	"symbolic-execution-course/internal/memory"
+	issa "symbolic-execution-course/internal/ssa"
 	"symbolic-execution-course/internal/symbolic"
 
-	"github.com/LastPossum/kamino"
 	"golang.org/x/tools/go/ssa"
 )
 
@@ -18,29 +19,117 @@ type Interpreter struct {
 	Analyser      *Analyser
 	PathCondition symbolic.SymbolicExpression
 	Heap          memory.Memory
+	Branches      []Branch
+	// Panic - инструкция panic, которой завершился путь, либо nil
+	Panic *ssa.Panic
+}
+
+// Branch - направление условного перехода, выбранное на пути исполнения
+type Branch struct {
+	// Pos - позиция условия в исходном коде
+	Pos token.Position
+	// Source - текст условия в исходном коде, например "x > 10"
+	Source string
+	// Condition - символьное значение условия
+	Condition symbolic.SymbolicExpression
+	// Taken - выполнено ли условие на этом пути
+	Taken bool
 }
 
 type CallStackFrame struct {
-	Function     *ssa.Function
-	LocalMemory  map[ssa.Value]symbolic.SymbolicExpression
-	ReturnValue  []symbolic.SymbolicExpression
-	CurrentBlock int
-	PrevBlock    int
+	Function       *ssa.Function
+	LocalMemory    map[ssa.Value]symbolic.SymbolicExpression
+	FieldAddresses map[ssa.Value]FieldAddress
+	ReturnValue    []symbolic.SymbolicExpression
+	ReturnInstr    *ssa.Return
+	CurrentBlock   int
+	PrevBlock      int
+	// LoopIterations - число обратных переходов в каждый цикл функции по заголовку цикла
+	LoopIterations map[*ssa.BasicBlock]int
+}
+
+// FieldAddress - адрес поля объекта в символьной памяти (результат ssa.FieldAddr)
+type FieldAddress struct {
+	Object *symbolic.Ref
+	Field  int
+}
+
+// ConvertType сопоставляет типу Go тип символьного выражения
+func ConvertType(tpe types.Type) (symbolic.ExpressionType, error) {
+	if _, ok := tpe.(*types.TypeParam); ok {
+		return 0, &issa.UnsupportedError{Construct: fmt.Sprintf("type parameter %s", tpe)}
+	}
+
+	res, ok := convertType(tpe)
+	if !ok {
+		return 0, &issa.UnsupportedError{Construct: fmt.Sprintf("type %s", tpe)}
+	}
+	return res, nil
 }
 
-func ConvertType(tpe types.Type) symbolic.ExpressionType {
-	switch tpe.Underlying().(*types.Basic).Kind() {
+// convertType сопоставляет базовому типу Go или []byte тип символьного выражения
+func convertType(tpe types.Type) (symbolic.ExpressionType, bool) {
+	if isBytes(tpe) {
+		return symbolic.BytesType, true
+	}
+
+	basic, ok := tpe.Underlying().(*types.Basic)
+	if !ok {
+		return 0, false
+	}
+
+	switch basic.Kind() {
 	case types.Bool:
-		return symbolic.BoolType
+		return symbolic.BoolType, true
 	case types.Int:
-		return symbolic.IntType
+		return symbolic.IntType, true
+	case types.Int8:
+		return symbolic.Int8Type, true
+	case types.Int16:
+		return symbolic.Int16Type, true
+	case types.Int32:
+		return symbolic.Int32Type, true
+	case types.Int64:
+		return symbolic.Int64Type, true
+	case types.Uint:
+		return symbolic.UintType, true
+	case types.Uint8:
+		return symbolic.Uint8Type, true
+	case types.Uint16:
+		return symbolic.Uint16Type, true
+	case types.Uint32:
+		return symbolic.Uint32Type, true
+	case types.Uint64:
+		return symbolic.Uint64Type, true
+	case types.Uintptr:
+		return symbolic.UintptrType, true
 	case types.UntypedFloat, types.Float64:
-		return symbolic.FloatType
+		return symbolic.FloatType, true
+	case types.Float32:
+		return symbolic.Float32Type, true
+	case types.String, types.UntypedString:
+		return symbolic.StringType, true
 	default:
-		panic("unexpected types.BasicKind")
+		return 0, false
 	}
 }
 
+// isBytes сообщает, является ли тип срезом байт
+func isBytes(tpe types.Type) bool {
+	slice, ok := tpe.Underlying().(*types.Slice)
+	if !ok {
+		return false
+	}
+	elem, ok := slice.Elem().Underlying().(*types.Basic)
+	return ok && elem.Kind() == types.Byte
+}
+
+// isString сообщает, является ли тип строкой
+func isString(tpe types.Type) bool {
+	basic, ok := tpe.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsString != 0
+}
+
 func execBinOp(op token.Token, X, Y symbolic.SymbolicExpression) symbolic.SymbolicExpression {
 	switch op {
 	case token.ADD:
@@ -87,6 +176,15 @@ func execBinOp(op token.Token, X, Y symbolic.SymbolicExpression) symbolic.Symbol
 func execUnOp(op token.Token, X symbolic.SymbolicExpression) symbolic.SymbolicExpression {
 	switch op {
 	case token.SUB:
+		if X.Type().IsInteger() {
+			// Для беззнаковых типов -1 становится числом из одних единиц, и умножение
+			// на него даёт то же дополнение до 2^n, что и унарный минус в Go
+			return symbolic.NewBinaryOperation(X, symbolic.NewTypedIntConstant(-1, X.Type()), symbolic.MUL)
+		}
+		if X.Type().IsFloat() {
+			// Умножение на -1 меняет знак и у нуля, и у бесконечностей, как унарный минус
+			return symbolic.NewBinaryOperation(X, symbolic.NewTypedFloatConstant(-1, X.Type()), symbolic.MUL)
+		}
 		return symbolic.NewBinaryOperation(X, symbolic.NewIntConstant(-1), symbolic.MUL)
 	case token.NOT:
 		return symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{X}, symbolic.NOT)
@@ -97,11 +195,188 @@ func execUnOp(op token.Token, X symbolic.SymbolicExpression) symbolic.SymbolicEx
 	}
 }
 
+// checkSupported заранее проверяет, что интерпретатор умеет исполнять все
+// инструкции и константы функции, и сообщает о первой неподдерживаемой конструкции
+func checkSupported(graph *ssa.Function) error {
+	fset := graph.Prog.Fset
+
+	if graph.TypeParams().Len() > 0 && len(graph.TypeArgs()) == 0 {
+		return issa.NewUnsupportedError(fset, graph.Pos(), "generic function %s without type arguments", graph.Name())
+	}
+	if graph.Blocks == nil {
+		return issa.NewUnsupportedError(fset, graph.Pos(), "function %s without body", graph.Name())
+	}
+
+	for _, block := range graph.Blocks {
+		for _, instr := range block.Instrs {
+			pos := instr.Pos()
+			if pos == token.NoPos {
+				pos = graph.Pos()
+			}
+
+			switch instr := instr.(type) {
+			case *ssa.DebugRef:
+				// Отладочные ссылки не исполняются, их операнды не проверяются
+				continue
+			case *ssa.BinOp, *ssa.Phi, *ssa.FieldAddr, *ssa.Field, *ssa.Store, *ssa.If, *ssa.Jump, *ssa.Return:
+			case *ssa.UnOp:
+				switch instr.Op {
+				case token.SUB, token.NOT, token.XOR, token.MUL:
+				default:
+					return issa.NewUnsupportedError(fset, pos, "unary operator %s", instr.Op)
+				}
+			case *ssa.MakeInterface:
+				// Интерфейсные значения поддерживаются только как аргумент panic
+				for _, ref := range *instr.Referrers() {
+					if _, ok := ref.(*ssa.Panic); !ok {
+						if _, ok := ref.(*ssa.DebugRef); !ok {
+							return issa.NewUnsupportedError(fset, pos, "interface conversion %s", instr)
+						}
+					}
+				}
+				continue
+			case *ssa.Panic:
+			case *ssa.Call:
+				if !isSupportedCall(instr.Common()) {
+					return issa.NewUnsupportedError(fset, pos, "call %s", instr.Common())
+				}
+			case *ssa.Index:
+				// Индексирование массивов не поддерживается
+				if !isString(instr.X.Type()) || !isInteger(instr.Index.Type()) {
+					return issa.NewUnsupportedError(fset, pos, "index %s", instr)
+				}
+			case *ssa.Slice:
+				if !isString(instr.X.Type()) && !isBytes(instr.X.Type()) || instr.Max != nil ||
+					instr.Low != nil && !isInteger(instr.Low.Type()) || instr.High != nil && !isInteger(instr.High.Type()) {
+					return issa.NewUnsupportedError(fset, pos, "slice %s", instr)
+				}
+			case *ssa.Convert:
+				if !isSupportedConversion(instr.X.Type(), instr.Type()) {
+					return issa.NewUnsupportedError(fset, pos, "conversion %s", instr)
+				}
+			case *ssa.ChangeType:
+				// Смена именованного типа при том же базовом типе не меняет значения
+				if _, ok := convertType(instr.Type()); !ok {
+					return issa.NewUnsupportedError(fset, pos, "conversion %s", instr)
+				}
+			case *ssa.Alloc:
+				if _, ok := instr.Type().(*types.Pointer).Elem().Underlying().(*types.Struct); !ok {
+					return issa.NewUnsupportedError(fset, pos, "allocation of %s", instr.Type())
+				}
+			default:
+				return issa.NewUnsupportedError(fset, pos, "instruction %T (%s)", instr, instr)
+			}
+
+			for _, operand := range instr.Operands(nil) {
+				if c, ok := (*operand).(*ssa.Const); ok && !isSupportedConst(c) {
+					return issa.NewUnsupportedError(fset, pos, "constant %s", c)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// stringFunctions - функции пакета strings, выражаемые операциями над строками
+var stringFunctions = map[string]symbolic.StringOperator{
+	"HasPrefix": symbolic.HAS_PREFIX,
+	"HasSuffix": symbolic.HAS_SUFFIX,
+	"Contains":  symbolic.CONTAINS,
+	"Index":     symbolic.INDEX,
+}
+
+// isSupportedCall сообщает, умеет ли интерпретатор исполнить вызов: поддерживаются
+// встроенные min и max над целыми числами, len над строками и срезами байт
+// и функции пакета strings из stringFunctions
+func isSupportedCall(call *ssa.CallCommon) bool {
+	if fn := call.StaticCallee(); fn != nil {
+		_, ok := stringFunctions[fn.Name()]
+		return ok && fn.Pkg != nil && fn.Pkg.Pkg.Path() == "strings"
+	}
+
+	builtin, ok := call.Value.(*ssa.Builtin)
+	if !ok {
+		return false
+	}
+
+	switch builtin.Name() {
+	case "len":
+		return isString(call.Args[0].Type()) || isBytes(call.Args[0].Type())
+	case "min", "max":
+		// Для чисел с плавающей точкой min и max по-особому обрабатывают NaN и -0
+		exprType, ok := convertType(call.Signature().Results().At(0).Type())
+		return ok && exprType.IsInteger()
+	}
+	return false
+}
+
+// isInteger сообщает, является ли тип целочисленным
+func isInteger(tpe types.Type) bool {
+	basic, ok := tpe.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsInteger != 0
+}
+
+// isSupportedConversion сообщает, умеет ли интерпретатор исполнить преобразование:
+// между числовыми типами и между string и []byte
+func isSupportedConversion(from, to types.Type) bool {
+	if (isString(from) || isBytes(from)) && (isString(to) || isBytes(to)) {
+		return true
+	}
+
+	source, ok := convertType(from)
+	if !ok {
+		return false
+	}
+	target, ok := convertType(to)
+	if !ok {
+		return false
+	}
+	return (source.IsInteger() || source.IsFloat()) && (target.IsInteger() || target.IsFloat())
+}
+
+// isSupportedConst сообщает, умеет ли resolveExpression преобразовать константу
+func isSupportedConst(c *ssa.Const) bool {
+	basic, ok := c.Type().Underlying().(*types.Basic)
+	if !ok {
+		return false
+	}
+
+	if basic.Kind() == types.UntypedInt {
+		return true
+	}
+	_, ok = convertType(basic)
+	return ok
+}
+
+// fork создаёт независимую копию состояния для новой ветви исполнения.
+// SSA функции и символьные выражения неизменяемы и разделяются между копиями,
+// поэтому копируются только кадры стека и память.
+func (interpreter *Interpreter) fork() *Interpreter {
+	res := *interpreter
+	res.Branches = slices.Clone(interpreter.Branches)
+	res.CallStack = make([]CallStackFrame, len(interpreter.CallStack))
+	for i, frame := range interpreter.CallStack {
+		frame.LocalMemory = maps.Clone(frame.LocalMemory)
+		frame.FieldAddresses = maps.Clone(frame.FieldAddresses)
+		frame.ReturnValue = slices.Clone(frame.ReturnValue)
+		frame.LoopIterations = maps.Clone(frame.LoopIterations)
+		res.CallStack[i] = frame
+	}
+
+	// Записи в память одной ветви не должны быть видны в другой
+	res.Heap = interpreter.Heap.Clone()
+	return &res
+}
+
 func (interpreter *Interpreter) frame() *CallStackFrame {
 	return &interpreter.CallStack[len(interpreter.CallStack)-1]
 }
 
 func (interpreter *Interpreter) interpretCurrentBlock() []Interpreter {
+	frame := interpreter.frame()
+	interpreter.Analyser.Visited[frame.Function.Blocks[frame.CurrentBlock]] = true
+
 	var res []Interpreter
 	nonPhis := interpreter.executePhis()
 	for _, instr := range nonPhis {
@@ -149,68 +424,248 @@ func (interpreter *Interpreter) interpretDynamically(element ssa.Instruction) []
 		return nil
 
 	case *ssa.UnOp:
+		if element.Op == token.MUL {
+			interpreter.frame().LocalMemory[element] = interpreter.load(element.X)
+			return nil
+		}
 		X := interpreter.resolveExpression(element.X)
 		interpreter.frame().LocalMemory[element] = execUnOp(element.Op, X)
 		return nil
 
+	case *ssa.FieldAddr:
+		interpreter.frame().FieldAddresses[element] = FieldAddress{
+			Object: interpreter.resolveObject(element.X),
+			Field:  element.Field,
+		}
+		return nil
+
+	case *ssa.Field:
+		object := interpreter.resolveObject(element.X)
+		interpreter.frame().LocalMemory[element] = interpreter.Heap.GetFieldValue(object, element.Field)
+		return nil
+
+	case *ssa.Alloc:
+		if _, ok := element.Type().(*types.Pointer).Elem().Underlying().(*types.Struct); !ok {
+			panic(fmt.Sprintf("unexpected allocation type: %s", element.Type()))
+		}
+		interpreter.frame().LocalMemory[element] = interpreter.Heap.Allocate(symbolic.ObjectType)
+		return nil
+
+	case *ssa.Store:
+		// Структуры в Go копируются при присваивании, поэтому запись структуры
+		// целиком копирует её поля, а не связывает адрес с тем же объектом
+		_, isStruct := element.Val.Type().Underlying().(*types.Struct)
+
+		if addr, ok := interpreter.frame().FieldAddresses[element.Addr]; ok {
+			value := interpreter.resolveExpression(element.Val)
+			if isStruct {
+				value = interpreter.copyObject(interpreter.resolveObject(element.Val), element.Val.Type())
+			}
+			interpreter.Heap.AssignField(addr.Object, addr.Field, value)
+			return nil
+		}
+		if dst, ok := interpreter.resolveExpression(element.Addr).(*symbolic.Ref); ok && isStruct {
+			interpreter.copyFields(dst, interpreter.resolveObject(element.Val), element.Val.Type())
+			return nil
+		}
+		panic(fmt.Sprintf("unexpected store address: %#v", element.Addr))
+
+	case *ssa.DebugRef, *ssa.MakeInterface:
+		return nil
+
+	case *ssa.Call:
+		interpreter.frame().LocalMemory[element] = interpreter.call(element.Common())
+		return nil
+
+	case *ssa.Index:
+		X := interpreter.resolveExpression(element.X)
+		index := interpreter.resolveIndex(element.Index)
+		interpreter.frame().LocalMemory[element] = symbolic.NewStringOperation(symbolic.AT, X, index)
+		return nil
+
+	case *ssa.Slice:
+		X := interpreter.resolveExpression(element.X)
+		var low, high symbolic.SymbolicExpression = symbolic.NewIntConstant(0), symbolic.NewStringOperation(symbolic.LEN, X)
+		if element.Low != nil {
+			low = interpreter.resolveIndex(element.Low)
+		}
+		if element.High != nil {
+			high = interpreter.resolveIndex(element.High)
+		}
+		interpreter.frame().LocalMemory[element] = symbolic.NewStringOperation(symbolic.SLICE, X, low, high)
+		return nil
+
+	case *ssa.Convert:
+		X := interpreter.resolveExpression(element.X)
+		switch {
+		case isBytes(element.Type()) && isString(element.X.Type()):
+			X = symbolic.NewStringOperation(symbolic.TO_BYTES, X)
+		case isString(element.Type()) && isBytes(element.X.Type()):
+			X = symbolic.NewStringOperation(symbolic.FROM_BYTES, X)
+		default:
+			target, _ := convertType(element.Type())
+			X = symbolic.NewConversion(X, target)
+		}
+		interpreter.frame().LocalMemory[element] = X
+		return nil
+
+	case *ssa.ChangeType:
+		interpreter.frame().LocalMemory[element] = interpreter.resolveExpression(element.X)
+		return nil
+
 	case *ssa.If:
-		cond := interpreter.resolveExpression(element.Cond)
-		intTrue, _ := kamino.Clone(interpreter)
-		intFalse, _ := kamino.Clone(interpreter)
+		expressions := interpreter.Analyser.Expressions
+		cond := expressions.Intern(symbolic.Simplify(interpreter.resolveExpression(element.Cond)))
+		intTrue := interpreter.fork()
+		intFalse := interpreter.fork()
 
-		// TODO: Fix logic
-		intTrue.Analyser = interpreter.Analyser
-		intFalse.Analyser = interpreter.Analyser
+		sourceMap := interpreter.Analyser.SourceMap(interpreter.frame().Function)
+		branch := Branch{
+			Pos:       sourceMap.Position(element),
+			Source:    sourceMap.Expr(element.Cond),
+			Condition: cond,
+		}
+		branch.Taken = true
+		intTrue.Branches = append(intTrue.Branches, branch)
+		branch.Taken = false
+		intFalse.Branches = append(intFalse.Branches, branch)
 
 		succs := interpreter.frame().Function.Blocks[interpreter.frame().CurrentBlock].Succs
 
-		intTrue.PathCondition = symbolic.NewLogicalOperation(
+		// Условия путей упрощаются и интернируются при каждом ветвлении, чтобы не накапливать
+		// вложенные конъюнкции и не дублировать общие подвыражения в разных состояниях.
+		// Ветвь, условие которой свелось к false, недостижима и отбрасывается.
+		intTrue.PathCondition = expressions.Intern(symbolic.Simplify(symbolic.NewLogicalOperation(
 			[]symbolic.SymbolicExpression{intTrue.PathCondition, cond},
 			symbolic.AND,
-		)
-		intTrue.frame().CurrentBlock = succs[0].Index
-		intTrue.frame().PrevBlock = interpreter.frame().CurrentBlock
-		intFalse.PathCondition = symbolic.NewLogicalOperation(
+		)))
+		intFalse.PathCondition = expressions.Intern(symbolic.Simplify(symbolic.NewLogicalOperation(
 			[]symbolic.SymbolicExpression{
 				intFalse.PathCondition,
 				symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{cond}, symbolic.NOT),
 			},
 			symbolic.AND,
-		)
-		intFalse.frame().CurrentBlock = succs[1].Index
-		intFalse.frame().PrevBlock = interpreter.frame().CurrentBlock
-		return []Interpreter{*intTrue, *intFalse}
+		)))
+
+		var res []Interpreter
+		for i, state := range []*Interpreter{intTrue, intFalse} {
+			if c, ok := state.PathCondition.(*symbolic.BoolConstant); ok && !c.Value {
+				continue
+			}
+			if state.jump(succs[i]) {
+				res = append(res, *state)
+			}
+		}
+		return res
 
-		// case *ssa.Alloc:
 	case *ssa.Jump:
-		interpreter.frame().PrevBlock = interpreter.frame().CurrentBlock
-		interpreter.frame().CurrentBlock = element.Block().Succs[0].Index
+		if !interpreter.jump(element.Block().Succs[0]) {
+			return []Interpreter{}
+		}
 		return []Interpreter{*interpreter}
 
 	case *ssa.Return:
 		results := make([]symbolic.SymbolicExpression, len(element.Results))
 		for i, res := range element.Results {
-			results[i] = interpreter.resolveExpression(res)
+			results[i] = interpreter.Analyser.Expressions.Intern(symbolic.Simplify(interpreter.resolveExpression(res)))
 		}
 		interpreter.frame().ReturnValue = results
+		interpreter.frame().ReturnInstr = element
 		interpreter.Analyser.Results = append(interpreter.Analyser.Results, *interpreter)
 		return []Interpreter{}
+
+	case *ssa.Panic:
+		// Путь, завершившийся паникой, считается найденной ошибкой
+		interpreter.Panic = element
+		interpreter.Analyser.Results = append(interpreter.Analyser.Results, *interpreter)
+		return []Interpreter{}
+
 	default:
 		panic(fmt.Sprintf("unexpected ssa.Instruction: %#v", element))
 	}
 }
 
+// jump переводит исполнение в блок to и учитывает проход по обратному ребру цикла.
+// Возвращает false, если цикл уже пройден Analyser.LoopBound раз и путь следует отбросить.
+func (interpreter *Interpreter) jump(to *ssa.BasicBlock) bool {
+	frame := interpreter.frame()
+	from := frame.Function.Blocks[frame.CurrentBlock]
+	frame.PrevBlock = from.Index
+	frame.CurrentBlock = to.Index
+
+	loops := interpreter.Analyser.LoopInfo(frame.Function)
+	if !loops.IsHeader(to) {
+		return true
+	}
+	if frame.LoopIterations == nil {
+		frame.LoopIterations = map[*ssa.BasicBlock]int{}
+	}
+	// Вход в цикл извне начинает отсчёт итераций заново, в том числе для вложенных циклов
+	if !loops.IsBackEdge(from, to) {
+		frame.LoopIterations[to] = 0
+		return true
+	}
+
+	frame.LoopIterations[to]++
+	bound := interpreter.Analyser.LoopBound
+	return bound <= 0 || frame.LoopIterations[to] <= bound
+}
+
+// call исполняет поддерживаемый вызов (см. isSupportedCall). Функции пакета strings
+// и len становятся операциями над строками, а min и max выражаются через условные выражения.
+func (interpreter *Interpreter) call(call *ssa.CallCommon) symbolic.SymbolicExpression {
+	args := make([]symbolic.SymbolicExpression, len(call.Args))
+	for i, arg := range call.Args {
+		args[i] = interpreter.resolveExpression(arg)
+	}
+
+	if fn := call.StaticCallee(); fn != nil {
+		return symbolic.NewStringOperation(stringFunctions[fn.Name()], args...)
+	}
+	if call.Value.Name() == "len" {
+		return symbolic.NewStringOperation(symbolic.LEN, args[0])
+	}
+
+	op := symbolic.LT
+	if call.Value.Name() == "max" {
+		op = symbolic.GT
+	}
+
+	res := args[0]
+	for _, x := range args[1:] {
+		res = symbolic.Ite(symbolic.NewBinaryOperation(x, res, op), x, res)
+	}
+	return res
+}
+
 func (interpreter *Interpreter) resolveExpression(value ssa.Value) symbolic.SymbolicExpression {
 	switch value := value.(type) {
 	case *ssa.Const:
-		switch value.Type().Underlying().(*types.Basic).Kind() {
-		case types.Int, types.Uint:
-			return symbolic.NewIntConstant(value.Int64())
+		basic := value.Type().Underlying().(*types.Basic)
+		if basic.Info()&types.IsInteger != 0 {
+			exprType, ok := convertType(basic)
+			if !ok {
+				exprType = symbolic.IntType
+			}
+			if basic.Info()&types.IsUnsigned != 0 {
+				v, _ := constant.Uint64Val(value.Value)
+				return symbolic.NewTypedIntConstant(int64(v), exprType)
+			}
+			v, _ := constant.Int64Val(value.Value)
+			return symbolic.NewTypedIntConstant(v, exprType)
+		}
+
+		switch basic.Kind() {
 		case types.Bool:
 			return symbolic.NewBoolConstant(constant.BoolVal(value.Value))
 		case types.UntypedFloat, types.Float64:
 			v, _ := constant.Float64Val(value.Value)
 			return symbolic.NewFloatConstant(v)
+		case types.Float32:
+			v, _ := constant.Float32Val(value.Value)
+			return symbolic.NewTypedFloatConstant(float64(v), symbolic.Float32Type)
+		case types.String, types.UntypedString:
+			return symbolic.NewStringConstant(constant.StringVal(value.Value))
 		default:
 			panic(fmt.Sprintf("unexpected value.Kind(): %#v", value.Type().Underlying().(*types.Basic).Kind()))
 		}
@@ -222,3 +677,58 @@ func (interpreter *Interpreter) resolveExpression(value ssa.Value) symbolic.Symb
 		panic(fmt.Sprintf("unexpected ssa.Value: %#v", value))
 	}
 }
+
+// resolveIndex возвращает индекс строки или среза, приведённый к типу int
+func (interpreter *Interpreter) resolveIndex(value ssa.Value) symbolic.SymbolicExpression {
+	index := interpreter.resolveExpression(value)
+	if index.Type() != symbolic.IntType {
+		return symbolic.NewConversion(index, symbolic.IntType)
+	}
+	return index
+}
+
+// resolveObject возвращает ссылку на объект, которым является value,
+// либо объект, лежащий в поле по адресу value
+func (interpreter *Interpreter) resolveObject(value ssa.Value) *symbolic.Ref {
+	if addr, ok := interpreter.frame().FieldAddresses[value]; ok {
+		return interpreter.Heap.GetFieldValue(addr.Object, addr.Field).(*symbolic.Ref)
+	}
+
+	ref, ok := interpreter.resolveExpression(value).(*symbolic.Ref)
+	if !ok {
+		panic(fmt.Sprintf("unexpected object value: %#v", value))
+	}
+	return ref
+}
+
+// copyObject размещает в памяти новый объект с копией полей объекта src структурного типа tpe
+func (interpreter *Interpreter) copyObject(src *symbolic.Ref, tpe types.Type) *symbolic.Ref {
+	dst := interpreter.Heap.Allocate(symbolic.ObjectType)
+	interpreter.copyFields(dst, src, tpe)
+	return dst
+}
+
+// copyFields копирует поля объекта src структурного типа tpe в объект dst.
+// Вложенные структуры копируются рекурсивно, неопределённые поля пропускаются.
+func (interpreter *Interpreter) copyFields(dst, src *symbolic.Ref, tpe types.Type) {
+	st := tpe.Underlying().(*types.Struct)
+	for i := 0; i < st.NumFields(); i++ {
+		value, err := interpreter.Heap.GetFieldValueChecked(src, i)
+		if err != nil {
+			continue
+		}
+		if _, ok := st.Field(i).Type().Underlying().(*types.Struct); ok {
+			value = interpreter.copyObject(value.(*symbolic.Ref), st.Field(i).Type())
+		}
+		interpreter.Heap.AssignField(dst, i, value)
+	}
+}
+
+// load читает значение по адресу: поле объекта или сам объект для указателя на структуру
+func (interpreter *Interpreter) load(addr ssa.Value) symbolic.SymbolicExpression {
+	if fieldAddr, ok := interpreter.frame().FieldAddresses[addr]; ok {
+		return interpreter.Heap.GetFieldValue(fieldAddr.Object, fieldAddr.Field)
+	}
+
+	return interpreter.resolveObject(addr)
+}
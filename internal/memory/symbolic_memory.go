@@ -1,6 +1,20 @@
 package memory
 
-import "symbolic-execution-course/internal/symbolic"
+import (
+	"errors"
+	"maps"
+
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// Ошибки доступа к памяти. Методы ...Checked возвращают их,
+// а остальные методы паникуют с их текстом.
+var (
+	ErrIncorrectType   = errors.New("incorrect type")
+	ErrUndefinedObject = errors.New("undefined object")
+	ErrUndefinedField  = errors.New("undefined object field")
+	ErrUndefinedIndex  = errors.New("undefined array index")
+)
 
 type Memory interface {
 	Allocate(tpe symbolic.ExpressionType) *symbolic.Ref
@@ -12,6 +26,17 @@ type Memory interface {
 	AssignToArray(ref *symbolic.Ref, index int, value symbolic.SymbolicExpression)
 
 	GetFromArray(ref *symbolic.Ref, index int) symbolic.SymbolicExpression
+
+	AssignFieldChecked(ref *symbolic.Ref, fieldIdx int, value symbolic.SymbolicExpression) error
+
+	GetFieldValueChecked(ref *symbolic.Ref, fieldIdx int) (symbolic.SymbolicExpression, error)
+
+	AssignToArrayChecked(ref *symbolic.Ref, index int, value symbolic.SymbolicExpression) error
+
+	GetFromArrayChecked(ref *symbolic.Ref, index int) (symbolic.SymbolicExpression, error)
+
+	// Clone возвращает независимую копию памяти: записи в копию не видны в исходной памяти
+	Clone() Memory
 }
 
 type SymbolicMemory struct {
@@ -28,6 +53,22 @@ func NewSymbolicMemory() *SymbolicMemory {
 	}
 }
 
+// Clone копирует таблицы объектов. Символьные выражения неизменяемы и разделяются между копиями.
+func (mem *SymbolicMemory) Clone() Memory {
+	res := &SymbolicMemory{
+		c:               mem.c,
+		pool:            make(map[symbolic.ExpressionType]map[int64]symbolic.SymbolicExpression, len(mem.pool)),
+		arrayObjectPool: make(map[int64]map[int]symbolic.SymbolicExpression, len(mem.arrayObjectPool)),
+	}
+	for tpe, values := range mem.pool {
+		res.pool[tpe] = maps.Clone(values)
+	}
+	for ptr, fields := range mem.arrayObjectPool {
+		res.arrayObjectPool[ptr] = maps.Clone(fields)
+	}
+	return res
+}
+
 func (mem *SymbolicMemory) Allocate(tpe symbolic.ExpressionType) *symbolic.Ref {
 	mem.c += 1
 	switch tpe {
@@ -47,43 +88,98 @@ func (mem *SymbolicMemory) Allocate(tpe symbolic.ExpressionType) *symbolic.Ref {
 }
 
 func (mem *SymbolicMemory) AssignField(ref *symbolic.Ref, fieldIdx int, value symbolic.SymbolicExpression) {
-	if ref.Tpe != symbolic.ObjectType {
-		panic("incorrect type")
+	if err := mem.AssignFieldChecked(ref, fieldIdx, value); err != nil {
+		panic(err.Error())
+	}
+}
+
+// AssignFieldChecked записывает значение поля объекта,
+// возвращая ошибку вместо паники, если ref не ссылается на объект
+func (mem *SymbolicMemory) AssignFieldChecked(ref *symbolic.Ref, fieldIdx int, value symbolic.SymbolicExpression) error {
+	object, err := mem.object(ref, symbolic.ObjectType)
+	if err != nil {
+		return err
 	}
 
-	mem.arrayObjectPool[ref.Ptr][fieldIdx] = value
+	object[fieldIdx] = value
+	return nil
 }
 
 func (mem *SymbolicMemory) GetFieldValue(ref *symbolic.Ref, fieldIdx int) symbolic.SymbolicExpression {
-	if ref.Tpe != symbolic.ObjectType {
-		panic("incorrect type")
+	value, err := mem.GetFieldValueChecked(ref, fieldIdx)
+	if err != nil {
+		panic(err.Error())
+	}
+	return value
+}
+
+// GetFieldValueChecked читает значение поля объекта,
+// возвращая ошибку вместо паники, если ref не ссылается на объект или поле не записано
+func (mem *SymbolicMemory) GetFieldValueChecked(ref *symbolic.Ref, fieldIdx int) (symbolic.SymbolicExpression, error) {
+	object, err := mem.object(ref, symbolic.ObjectType)
+	if err != nil {
+		return nil, err
 	}
 
-	value, ok := mem.arrayObjectPool[ref.Ptr][fieldIdx]
+	value, ok := object[fieldIdx]
 	if !ok {
-		panic("undefined object field")
+		return nil, ErrUndefinedField
 	}
 
-	return value
+	return value, nil
 }
 
 func (mem *SymbolicMemory) AssignToArray(ref *symbolic.Ref, index int, value symbolic.SymbolicExpression) {
-	if ref.Tpe != symbolic.ArrayType {
-		panic("incorrect type")
+	if err := mem.AssignToArrayChecked(ref, index, value); err != nil {
+		panic(err.Error())
+	}
+}
+
+// AssignToArrayChecked записывает элемент массива,
+// возвращая ошибку вместо паники, если ref не ссылается на массив
+func (mem *SymbolicMemory) AssignToArrayChecked(ref *symbolic.Ref, index int, value symbolic.SymbolicExpression) error {
+	array, err := mem.object(ref, symbolic.ArrayType)
+	if err != nil {
+		return err
 	}
 
-	mem.arrayObjectPool[ref.Ptr][index] = value
+	array[index] = value
+	return nil
 }
 
 func (mem *SymbolicMemory) GetFromArray(ref *symbolic.Ref, index int) symbolic.SymbolicExpression {
-	if ref.Tpe != symbolic.ArrayType {
-		panic("incorrect type")
+	value, err := mem.GetFromArrayChecked(ref, index)
+	if err != nil {
+		panic(err.Error())
+	}
+	return value
+}
+
+// GetFromArrayChecked читает элемент массива,
+// возвращая ошибку вместо паники, если ref не ссылается на массив или элемент не записан
+func (mem *SymbolicMemory) GetFromArrayChecked(ref *symbolic.Ref, index int) (symbolic.SymbolicExpression, error) {
+	array, err := mem.object(ref, symbolic.ArrayType)
+	if err != nil {
+		return nil, err
 	}
 
-	value, ok := mem.arrayObjectPool[ref.Ptr][index]
+	value, ok := array[index]
 	if !ok {
-		panic("undefined array index")
+		return nil, ErrUndefinedIndex
 	}
 
-	return value
+	return value, nil
+}
+
+// object возвращает содержимое объекта или массива, на который указывает ref
+func (mem *SymbolicMemory) object(ref *symbolic.Ref, tpe symbolic.ExpressionType) (map[int]symbolic.SymbolicExpression, error) {
+	if ref == nil || ref.Tpe != tpe {
+		return nil, ErrIncorrectType
+	}
+
+	object, ok := mem.arrayObjectPool[ref.Ptr]
+	if !ok {
+		return nil, ErrUndefinedObject
+	}
+	return object, nil
 }
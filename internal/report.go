@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+	issa "symbolic-execution-course/internal/ssa"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// Report описывает завершённый путь исполнения в терминах исходного кода:
+// входные переменные, пройденные ветвления с позициями и текстом условий,
+// условие пути и возвращаемые значения.
+func (interpreter *Interpreter) Report() string {
+	var sb strings.Builder
+
+	if len(interpreter.Analyser.Inputs) > 0 {
+		sb.WriteString("inputs:\n")
+		for _, input := range interpreter.Analyser.Inputs {
+			fmt.Fprintf(&sb, "  %s: %s\n", location(input.Pos), input.Name)
+		}
+	}
+
+	if len(interpreter.Branches) > 0 {
+		sb.WriteString("branches:\n")
+		for _, branch := range interpreter.Branches {
+			fmt.Fprintf(&sb, "  %s: if %s -> %t\n", location(branch.Pos), branch.Source, branch.Taken)
+		}
+	}
+
+	fmt.Fprintf(&sb, "path condition: %s\n", interpreter.PathCondition)
+
+	frame := interpreter.frame()
+	if interpreter.Panic != nil {
+		sourceMap := interpreter.Analyser.SourceMap(frame.Function)
+		fmt.Fprintf(&sb, "panic at %s: %s\n", location(sourceMap.Position(interpreter.Panic)), panicMessage(sourceMap, interpreter.Panic))
+		return sb.String()
+	}
+	if frame.ReturnInstr == nil {
+		return sb.String()
+	}
+
+	sourceMap := interpreter.Analyser.SourceMap(frame.Function)
+	fmt.Fprintf(&sb, "return at %s:\n", location(sourceMap.Position(frame.ReturnInstr)))
+	for i, res := range frame.ReturnInstr.Results {
+		fmt.Fprintf(&sb, "  %s = %s\n", sourceMap.Expr(res), frame.ReturnValue[i])
+	}
+
+	return sb.String()
+}
+
+// location форматирует позицию как file:line
+func location(pos token.Position) string {
+	if !pos.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%s:%d", pos.Filename, pos.Line)
+}
+
+// panicMessage возвращает текст аргумента panic в исходном коде
+func panicMessage(sourceMap *issa.SourceMap, instr *ssa.Panic) string {
+	if conv, ok := instr.X.(*ssa.MakeInterface); ok {
+		return sourceMap.Expr(conv.X)
+	}
+	return sourceMap.Expr(instr.X)
+}
@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"fmt"
+	issa "symbolic-execution-course/internal/ssa"
+	"symbolic-execution-course/internal/translator"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// Session - сеанс анализа, в котором SSA программа каждого исходного файла
+// строится один раз, а Z3 транслятор и его контекст разделяются между анализами.
+// Session не предназначен для одновременного использования из нескольких горутин.
+type Session struct {
+	Z3Translator *translator.Z3Translator
+	// Importer - способ импорта пакетов при построении программ
+	Importer issa.ImporterMode
+	// LoopBound - наибольшее число итераций каждого цикла на одном пути (см. Analyser.LoopBound)
+	LoopBound int
+	// StringBound - наибольшая длина строковых переменных в формулах Z3Translator (см. CheckPath)
+	StringBound int
+	// BlockLimit - наибольшее число базовых блоков, исполняемых за один анализ по всем путям;
+	// при его достижении анализ останавливается (см. Analyser.Truncated). 0 снимает ограничение
+	BlockLimit int
+	programs   map[[sha256.Size]byte]*issa.Program
+}
+
+const (
+	// DefaultLoopBound - ограничение числа итераций каждого цикла на одном пути по умолчанию
+	DefaultLoopBound = 3
+	// DefaultBlockLimit - ограничение числа исполняемых за анализ базовых блоков по умолчанию
+	DefaultBlockLimit = 10000
+)
+
+// NewSession создаёт сеанс анализа с пустым кэшем программ
+func NewSession() *Session {
+	return &Session{
+		Z3Translator: translator.NewZ3Translator(),
+		LoopBound:    DefaultLoopBound,
+		StringBound:  translator.DefaultStringBound,
+		BlockLimit:   DefaultBlockLimit,
+		programs:     map[[sha256.Size]byte]*issa.Program{},
+	}
+}
+
+// Program возвращает SSA программу исходного кода, строя её только при первом
+// обращении с таким содержимым. instances - запрашиваемые экземпляры обобщённых
+// функций (см. issa.Builder.Instantiate), они, как и режим импорта, входят в ключ кэша.
+func (session *Session) Program(source string, instances ...string) (*issa.Program, error) {
+	hash := sha256.New()
+	hash.Write([]byte(session.Importer.String()))
+	hash.Write([]byte{0})
+	hash.Write([]byte(source))
+	for _, instance := range instances {
+		hash.Write([]byte{0})
+		hash.Write([]byte(instance))
+	}
+
+	var key [sha256.Size]byte
+	hash.Sum(key[:0])
+
+	if program, ok := session.programs[key]; ok {
+		return program, nil
+	}
+
+	builder := issa.NewBuilder()
+	builder.Instantiate(instances...)
+	builder.UseImporter(session.Importer)
+
+	program, err := builder.ParseAndBuildProgram(source)
+	if err != nil {
+		return nil, err
+	}
+
+	session.programs[key] = program
+	return program, nil
+}
+
+// Analyse анализирует функцию functionName исходного кода source, переиспользуя
+// построенную ранее программу. Экземпляры обобщённых функций строятся отдельно.
+func (session *Session) Analyse(source string, functionName string) ([]Interpreter, error) {
+	var instances []string
+	if issa.IsInstanceName(functionName) {
+		instances = append(instances, functionName)
+	}
+
+	program, err := session.Program(source, instances...)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := program.Function(functionName)
+	if err != nil {
+		return nil, err
+	}
+
+	return session.AnalyseFunction(graph)
+}
+
+// AnalyseFunction запускает символьное исполнение уже построенной SSA функции.
+// Паника интерпретатора на непредусмотренной конструкции возвращается как ошибка,
+// чтобы один неудачный анализ не завершал весь процесс. Путь с такой паникой
+// отбрасывается, а результаты остальных путей возвращаются вместе с ошибкой.
+func (session *Session) AnalyseFunction(graph *ssa.Function) ([]Interpreter, error) {
+	analyser, err := session.run(graph)
+	if analyser == nil {
+		return nil, err
+	}
+	return analyser.Results, err
+}
+
+// CheckPath проверяет выполнимость условия пути. Ответ Z3Translator для строк
+// не длиннее StringBound перепроверяется без границы (см. translator.Z3Translator.Check),
+// поэтому translator.Unknown означает, что выполнимость пути не установлена.
+func (session *Session) CheckPath(interpreter *Interpreter) (translator.Verdict, error) {
+	session.Z3Translator.Reset()
+	session.Z3Translator.SetStringBound(session.StringBound)
+	verdict, _, err := session.Z3Translator.Check(interpreter.PathCondition)
+	return verdict, err
+}
+
+// run исполняет функцию и возвращает анализатор с собранными результатами.
+// Если исполнение некоторых путей прервано паникой интерпретатора, вместе с
+// анализатором возвращается ошибка первого из них.
+func (session *Session) run(graph *ssa.Function) (*Analyser, error) {
+	// Переменные разных функций могут совпадать по имени, но отличаться типом
+	session.Z3Translator.Reset()
+
+	analyser, err := createAnalyser(graph, &RandomPathSelector{}, session.Z3Translator)
+	if err != nil {
+		return nil, err
+	}
+	analyser.LoopBound = session.LoopBound
+
+	failed := 0
+	for i := 0; analyser.StatesQueue.Len() > 0; i++ {
+		if session.BlockLimit > 0 && i >= session.BlockLimit {
+			analyser.Truncated = true
+			break
+		}
+		state := analyser.StatesQueue.Pop().(*Item).value
+		new_states, stepErr := step(&state)
+		if stepErr != nil {
+			if failed == 0 {
+				err = fmt.Errorf("analysis of %s failed: %w", graph, stepErr)
+			}
+			failed++
+			continue
+		}
+		for _, new_state := range new_states {
+			analyser.StatesQueue.Push(&Item{
+				value:    new_state,
+				priority: analyser.PathSelector.CalculatePriority(new_state),
+			})
+		}
+	}
+
+	if failed > 1 {
+		err = fmt.Errorf("%w (%d paths failed)", err, failed)
+	}
+	return analyser, err
+}
+
+// step исполняет текущий блок состояния, возвращая панику интерпретатора как ошибку
+func step(state *Interpreter) (states []Interpreter, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return state.interpretCurrentBlock(), nil
+}
@@ -0,0 +1,198 @@
+package internal
+
+import (
+	"strings"
+	issa "symbolic-execution-course/internal/ssa"
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+	"testing"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+const sessionSource = `package main
+
+type P struct {
+	M map[int]int
+}
+
+func positive(x int) bool {
+	return x > 0
+}
+
+func flag(x bool) bool {
+	return !x
+}
+
+func field(p P) map[int]int {
+	return p.M
+}
+
+func long(s string) int {
+	if len(s) > 3 && len(s) < 5 {
+		return 1
+	}
+	if len(s) > 3 && len(s) < 2 {
+		return 2
+	}
+	return 0
+}
+
+func sum(n int) int {
+	s := 0
+	for i := 0; i < n; i++ {
+		s += i
+	}
+	return s
+}
+`
+
+func TestSessionCachesPrograms(t *testing.T) {
+	session := NewSession()
+
+	first, err := session.Program(sessionSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := session.Program(sessionSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Error("expected the cached program for the same source")
+	}
+
+	other, err := session.Program(sessionSource + "\nfunc other() {}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == first {
+		t.Error("expected a new program for a different source")
+	}
+
+	session.Importer = issa.ImporterSource
+	source, err := session.Program(sessionSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source == first {
+		t.Error("expected a new program for a different importer")
+	}
+	session.Importer = issa.ImporterAuto
+
+	results, err := session.Analyse(sessionSource, "positive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Analyser.Package != first.Packages[0] {
+		t.Error("expected the analysis to use the cached program")
+	}
+}
+
+func TestSessionReusesTranslator(t *testing.T) {
+	session := NewSession()
+	zt := session.Z3Translator
+
+	// Переменная x объявлена как целое до анализа функции, где x - булева
+	if _, err := zt.TranslateExpression(symbolic.NewSymbolicVariable("x", symbolic.IntType)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"positive", "flag"} {
+		results, err := session.Analyse(sessionSource, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if results[0].Analyser.Z3Translator != zt {
+			t.Errorf("%s: expected the session translator", name)
+		}
+	}
+
+	x, err := zt.TranslateExpression(symbolic.NewSymbolicVariable("x", symbolic.BoolType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := x.(z3.Bool); !ok {
+		t.Errorf("expected the translator to be reset between analyses, got %T", x)
+	}
+}
+
+func TestSessionRecoversPanics(t *testing.T) {
+	session := NewSession()
+
+	_, err := session.Analyse(sessionSource, "field")
+	if err == nil || !strings.Contains(err.Error(), "undefined object field") {
+		t.Fatalf("expected an analysis error, got %v", err)
+	}
+
+	// Сеанс остаётся пригодным после неудачного анализа
+	if _, err := session.Analyse(sessionSource, "positive"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSessionCheckPath(t *testing.T) {
+	session := NewSession()
+	// Пути с len(s) = 4 не укладываются в границу, но выполнимы
+	session.StringBound = 2
+
+	results, err := session.Analyse(sessionSource, "long")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verdicts := map[string][]translator.Verdict{}
+	for _, result := range results {
+		verdict, err := session.CheckPath(&result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ret := result.frame().ReturnValue[0].String()
+		verdicts[ret] = append(verdicts[ret], verdict)
+	}
+
+	expected := map[string]translator.Verdict{"1": translator.Sat, "2": translator.Unsat, "0": translator.Sat}
+	for ret, verdict := range expected {
+		if len(verdicts[ret]) == 0 {
+			t.Errorf("no path returns %s", ret)
+		}
+		for _, got := range verdicts[ret] {
+			if got != verdict {
+				t.Errorf("path returning %s: expected %s, got %s", ret, verdict, got)
+			}
+		}
+	}
+}
+
+func TestSessionBlockLimit(t *testing.T) {
+	session := NewSession()
+	program, err := session.Program(sessionSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph, err := program.Function("sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session.BlockLimit = 0
+	complete, err := session.run(graph)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Без ограничения исследуются все пути с не более чем LoopBound итерациями
+	if complete.Truncated || len(complete.Results) != session.LoopBound+1 {
+		t.Errorf("expected %d complete paths, got %d (truncated: %v)",
+			session.LoopBound+1, len(complete.Results), complete.Truncated)
+	}
+
+	session.BlockLimit = 5
+	truncated, err := session.run(graph)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !truncated.Truncated || len(truncated.Results) >= len(complete.Results) {
+		t.Errorf("expected a truncated analysis, got %d paths (truncated: %v)",
+			len(truncated.Results), truncated.Truncated)
+	}
+}
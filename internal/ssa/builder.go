@@ -4,18 +4,23 @@ package ssa
 import (
 	"fmt"
 	"go/ast"
-	"go/importer"
 	"go/parser"
 	"go/token"
 	"go/types"
+	"path/filepath"
+	"slices"
+	"strings"
 
+	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/ssa"
 	"golang.org/x/tools/go/ssa/ssautil"
 )
 
 // Builder отвечает за построение SSA из исходного кода Go
 type Builder struct {
-	fset *token.FileSet
+	fset      *token.FileSet
+	instances []string
+	importer  ImporterMode
 }
 
 // NewBuilder создаёт новый экземпляр Builder
@@ -25,29 +30,265 @@ func NewBuilder() *Builder {
 	}
 }
 
+// Program представляет SSA программу, построенную из загруженных пакетов
+type Program struct {
+	// Prog - SSA программа со всеми пакетами, включая зависимости
+	Prog *ssa.Program
+	// Packages - пакеты, соответствующие шаблонам загрузки
+	Packages []*ssa.Package
+	// Fset - набор файлов, по которому восстанавливаются позиции
+	Fset *token.FileSet
+}
+
+// buildMode - режим построения SSA. Обобщённые функции мономорфизируются,
+// чтобы интерпретатор исполнял тела экземпляров с конкретными типами.
+// Отладочная информация (ssa.DebugRef) нужна для сопоставления с исходным кодом.
+const buildMode = ssa.SanityCheckFunctions | ssa.InstantiateGenerics | ssa.GlobalDebug
+
+// Instantiate запрашивает построение экземпляров обобщённых функций для
+// LoadAndBuildSSA, например "Max[int]" или "(*Stack[string]).Push".
+// Экземпляры, уже используемые в загружаемом коде, запрашивать не нужно.
+func (b *Builder) Instantiate(names ...string) {
+	b.instances = append(b.instances, names...)
+}
+
+// UseImporter задаёт способ импорта пакетов для ParseAndBuildSSA и ParseAndBuildProgram.
+// По умолчанию используется ImporterAuto.
+func (b *Builder) UseImporter(mode ImporterMode) {
+	b.importer = mode
+}
+
 // ParseAndBuildSSA парсит исходный код Go и создаёт SSA представление
-// Возвращает SSA программу и функцию по имени
+// Возвращает SSA программу и функцию по имени.
+// Для обобщённой функции аргументы типа указываются в имени: "Max[int]".
 func (b *Builder) ParseAndBuildSSA(source string, funcName string) (*ssa.Function, error) {
-	file, err := parser.ParseFile(b.fset, "test.go", source, parser.ParseComments)
+	program, err := b.buildSource(source, append(slices.Clone(b.instances), funcName))
 	if err != nil {
 		return nil, err
 	}
 
+	return program.Function(funcName)
+}
+
+// ParseAndBuildProgram парсит исходный код одного файла и строит SSA для всего пакета,
+// чтобы затем искать в нём функции через Program.Function.
+// Экземпляры обобщённых функций запрашиваются заранее через Instantiate.
+func (b *Builder) ParseAndBuildProgram(source string) (*Program, error) {
+	return b.buildSource(source, b.instances)
+}
+
+// buildSource строит пакет из одного файла "test.go" и синтетического файла
+// со ссылками на запрошенные экземпляры обобщённых функций
+func (b *Builder) buildSource(source string, instances []string) (*Program, error) {
+	file, err := parser.ParseFile(b.fset, "test.go", source, parser.ParseComments)
+	if err != nil {
+		return nil, newParseError(err)
+	}
+
 	files := []*ast.File{file}
 
-	pkg := types.NewPackage(file.Name.Name, "")
+	var exprs []string
+	var pkg *types.Package
+	for _, name := range instances {
+		expr, ok := instanceExpr(name, file.Name.Name)
+		if !ok {
+			continue
+		}
+		if pkg == nil {
+			pkg = declarations(b.fset, file)
+		}
+		expr, err := checkInstance(b.fset, pkg, name, expr)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	if len(exprs) > 0 {
+		instances, err := parser.ParseFile(b.fset, "instances.go", instanceSource(file.Name.Name, exprs), 0)
+		if err != nil {
+			return nil, newParseError(err)
+		}
+		files = append(files, instances)
+	}
+
+	res, err := b.buildPackage(file.Name.Name, files, b.importer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Program{
+		Prog:     res.Prog,
+		Packages: []*ssa.Package{res},
+		Fset:     b.fset,
+	}, nil
+}
+
+// declarations проверяет типы файла без импортов, чтобы аргументы типа запрошенных
+// экземпляров разрешались в области видимости его пакета. Ошибки проверки
+// не возвращаются: о них сообщит построение пакета.
+func declarations(fset *token.FileSet, file *ast.File) *types.Package {
+	config := &types.Config{Error: func(error) {}}
+	pkg, _ := config.Check(file.Name.Name, fset, []*ast.File{file}, nil)
+	return pkg
+}
+
+// buildPackage проверяет типы файлов пакета и строит для него SSA.
+// В режиме ImporterAuto при неудачном импорте проверка повторяется по исходному коду.
+func (b *Builder) buildPackage(name string, files []*ast.File, mode ImporterMode) (*ssa.Package, error) {
+	imp, err := newImporter(mode, b.fset, files)
+	if err != nil {
+		return nil, err
+	}
+
+	typeErr := &TypeCheckError{}
+	config := &types.Config{
+		Importer: imp,
+		Error: func(err error) {
+			typeErr.Diagnostics = append(typeErr.Diagnostics, typeCheckDiagnostic(err))
+		},
+	}
 
-	res, _, err := ssautil.BuildPackage(
-		&types.Config{Importer: importer.Default()}, b.fset, pkg, files, ssa.SanityCheckFunctions)
+	res, _, err := ssautil.BuildPackage(config, b.fset, types.NewPackage(name, ""), files, buildMode)
+	if tracking, ok := imp.(*trackingImporter); ok && tracking.failed && mode == ImporterAuto {
+		return b.buildPackage(name, files, ImporterSource)
+	}
+	if len(typeErr.Diagnostics) > 0 {
+		return nil, typeErr
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	f := res.Func(funcName)
+	return res, nil
+}
 
-	if f == nil {
-		return nil, fmt.Errorf("no such function")
+// LoadAndBuildSSA загружает пакеты через golang.org/x/tools/go/packages
+// и строит SSA для всей программы. Шаблоны интерпретируются так же, как
+// в go build: каталог ("./pkg"), путь импорта или "./..." для всего модуля.
+// Поиск go.mod и разрешение импортов выполняются относительно dir.
+func (b *Builder) LoadAndBuildSSA(dir string, patterns ...string) (*Program, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"."}
 	}
 
-	return f, nil
+	cfg := &packages.Config{
+		Mode: packages.LoadAllSyntax,
+		Dir:  dir,
+		Fset: b.fset,
+	}
+
+	initial, err := loadPackages(cfg, patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	overlay, err := b.instanceOverlay(initial)
+	if err != nil {
+		return nil, err
+	}
+	if len(overlay) > 0 {
+		cfg.Overlay = overlay
+		initial, err = loadPackages(cfg, patterns)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	prog, pkgs := ssautil.AllPackages(initial, buildMode)
+	prog.Build()
+
+	res := &Program{
+		Prog: prog,
+		Fset: b.fset,
+	}
+	for _, pkg := range pkgs {
+		if pkg != nil {
+			res.Packages = append(res.Packages, pkg)
+		}
+	}
+
+	return res, nil
+}
+
+// loadPackages загружает пакеты и собирает ошибки загрузки всех зависимостей
+func loadPackages(cfg *packages.Config, patterns []string) ([]*packages.Package, error) {
+	initial, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	if len(initial) == 0 {
+		return nil, fmt.Errorf("no packages matched %s", strings.Join(patterns, " "))
+	}
+
+	var errs []packages.Error
+	packages.Visit(initial, nil, func(pkg *packages.Package) {
+		errs = append(errs, pkg.Errors...)
+	})
+	if len(errs) > 0 {
+		return nil, newPackagesError(errs)
+	}
+
+	return initial, nil
+}
+
+// instanceOverlay добавляет в каталоги пакетов, объявляющих запрошенные
+// обобщённые функции, файлы со ссылками на нужные экземпляры
+func (b *Builder) instanceOverlay(initial []*packages.Package) (map[string][]byte, error) {
+	overlay := map[string][]byte{}
+	for _, pkg := range initial {
+		if pkg.Types == nil || len(pkg.GoFiles) == 0 {
+			continue
+		}
+
+		var exprs []string
+		for _, name := range b.instances {
+			expr, ok := instanceExpr(name, pkg.PkgPath, pkg.Name)
+			if !ok || pkg.Types.Scope().Lookup(declName(expr)) == nil {
+				continue
+			}
+			expr, err := checkInstance(b.fset, pkg.Types, name, expr)
+			if err != nil {
+				return nil, err
+			}
+			exprs = append(exprs, expr)
+		}
+
+		if len(exprs) > 0 {
+			path := filepath.Join(filepath.Dir(pkg.GoFiles[0]), "ssa_instances.go")
+			overlay[path] = []byte(instanceSource(pkg.Name, exprs))
+		}
+	}
+	return overlay, nil
+}
+
+// Function ищет функцию по имени среди загруженных пакетов.
+// Допустимые формы имени описаны у lookupFunction. Функции из зависимостей,
+// не попавших под шаблоны загрузки, доступны только по квалифицированному имени.
+func (p *Program) Function(funcName string) (*ssa.Function, error) {
+	var found []*ssa.Function
+	for _, pkg := range p.Packages {
+		if f := lookupFunction(pkg, funcName); f != nil {
+			found = append(found, f)
+		}
+	}
+
+	if len(found) == 0 {
+		for _, pkg := range p.Prog.AllPackages() {
+			if hasQualifier(pkg, funcName) {
+				if f := lookupFunction(pkg, funcName); f != nil {
+					found = append(found, f)
+				}
+			}
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, &FunctionNotFoundError{Name: funcName}
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("ambiguous function name %s: found in %s and %s",
+			funcName, found[0].Pkg.Pkg.Path(), found[1].Pkg.Pkg.Path())
+	}
 }
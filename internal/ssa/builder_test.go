@@ -0,0 +1,581 @@
+package ssa
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// writeModule создаёт во временном каталоге модуль из переданных файлов
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadAndBuildSSAMultipleFiles(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/demo\n\ngo 1.21\n",
+		"main.go": `package main
+
+import "example.com/demo/util"
+
+func run(x int) int {
+	return helper(util.Double(x))
+}
+
+func main() {}
+`,
+		"helper.go": `package main
+
+func helper(x int) int {
+	if x > 10 {
+		return x
+	}
+	return 0
+}
+`,
+		"util/util.go": `package util
+
+func Double(x int) int {
+	return x * 2
+}
+`,
+	})
+
+	program, err := NewBuilder().LoadAndBuildSSA(dir, "./...")
+	if err != nil {
+		t.Fatalf("LoadAndBuildSSA: %v", err)
+	}
+
+	if len(program.Packages) != 2 {
+		t.Fatalf("expected 2 packages, got %d", len(program.Packages))
+	}
+
+	for _, name := range []string{"run", "helper", "Double"} {
+		fn, err := program.Function(name)
+		if err != nil {
+			t.Fatalf("Function(%q): %v", name, err)
+		}
+		pos := program.Fset.Position(fn.Pos())
+		if !pos.IsValid() || filepath.Base(pos.Filename) == "test.go" {
+			t.Errorf("Function(%q) has unexpected position %v", name, pos)
+		}
+	}
+
+	helper, _ := program.Function("helper")
+	if got := filepath.Base(program.Fset.Position(helper.Pos()).Filename); got != "helper.go" {
+		t.Errorf("helper declared in %s, expected helper.go", got)
+	}
+
+	if _, err := program.Function("missing"); err == nil {
+		t.Error("expected error for missing function")
+	}
+
+	double, err := program.Function("util.Double")
+	if err != nil || double.Name() != "Double" {
+		t.Errorf("Function(util.Double) = %v, %v", double, err)
+	}
+	if _, err := program.Function("example.com/demo/util.Double"); err != nil {
+		t.Errorf("Function by import path: %v", err)
+	}
+}
+
+const methodsSource = `package main
+
+type Person struct {
+	Name string
+	Age  int
+}
+
+func (p *Person) Grow(years int) {
+	p.Age += years
+}
+
+func (p Person) String() string {
+	return p.Name
+}
+
+func outer(x int) func() int {
+	return func() int {
+		inner := func() int { return x }
+		return inner()
+	}
+}
+`
+
+func TestParseAndBuildSSAResolvesEntryPoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"outer", "main.outer"},
+		{"main.outer", "main.outer"},
+		{"(*Person).Grow", "(*main.Person).Grow"},
+		{"Person.Grow", "(*main.Person).Grow"},
+		{"(*main.Person).Grow", "(*main.Person).Grow"},
+		{"Person.String", "(main.Person).String"},
+		{"(Person).String", "(main.Person).String"},
+		{"outer$1", "main.outer$1"},
+		{"outer$1$1", "main.outer$1$1"},
+	}
+
+	for _, tt := range tests {
+		fn, err := NewBuilder().ParseAndBuildSSA(methodsSource, tt.name)
+		if err != nil {
+			t.Errorf("ParseAndBuildSSA(%q): %v", tt.name, err)
+			continue
+		}
+		if fn.String() != tt.expected {
+			t.Errorf("ParseAndBuildSSA(%q) = %s, expected %s", tt.name, fn, tt.expected)
+		}
+	}
+
+	for _, name := range []string{"Person.Missing", "outer$2", "other.outer", "(*Missing).Grow"} {
+		if _, err := NewBuilder().ParseAndBuildSSA(methodsSource, name); err == nil {
+			t.Errorf("ParseAndBuildSSA(%q): expected error", name)
+		}
+	}
+}
+
+func TestParseAndBuildSSAErrors(t *testing.T) {
+	_, err := NewBuilder().ParseAndBuildSSA("package main\n\nfunc f( {", "f")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || len(parseErr.Diagnostics) == 0 {
+		t.Fatalf("expected ParseError, got %v", err)
+	}
+	if parseErr.Diagnostics[0].Pos.Line != 3 {
+		t.Errorf("unexpected parse error position: %v", parseErr.Diagnostics[0].Pos)
+	}
+
+	_, err = NewBuilder().ParseAndBuildSSA("package main\n\nfunc f() int {\n\treturn undefined\n}\n", "f")
+	var typeErr *TypeCheckError
+	if !errors.As(err, &typeErr) || len(typeErr.Diagnostics) == 0 {
+		t.Fatalf("expected TypeCheckError, got %v", err)
+	}
+	if pos := typeErr.Diagnostics[0].Pos; pos.Line != 4 || pos.Column != 9 {
+		t.Errorf("unexpected type error position: %v", pos)
+	}
+
+	_, err = NewBuilder().ParseAndBuildSSA("package main\n\nfunc f() {}\n", "g")
+	var notFound *FunctionNotFoundError
+	if !errors.As(err, &notFound) || notFound.Name != "g" {
+		t.Fatalf("expected FunctionNotFoundError, got %v", err)
+	}
+}
+
+func TestLoadAndBuildSSATypeErrors(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod":  "module example.com/broken\n\ngo 1.21\n",
+		"main.go": "package main\n\nfunc f() int {\n\treturn \"x\"\n}\n",
+	})
+
+	_, err := NewBuilder().LoadAndBuildSSA(dir, ".")
+	var typeErr *TypeCheckError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("expected TypeCheckError, got %v", err)
+	}
+	if pos := typeErr.Diagnostics[0].Pos; filepath.Base(pos.Filename) != "main.go" || pos.Line != 4 {
+		t.Errorf("unexpected type error position: %v", pos)
+	}
+}
+
+const genericsSource = `package main
+
+import "cmp"
+
+func Max[T cmp.Ordered](a, b T) T {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+type Stack[T any] struct {
+	items []T
+}
+
+func (s *Stack[T]) Push(item T) {
+	s.items = append(s.items, item)
+}
+
+func Pair[K comparable, V any](k K, v V) map[K]V {
+	return map[K]V{k: v}
+}
+`
+
+func TestParseAndBuildSSAInstantiatesGenerics(t *testing.T) {
+	tests := []struct {
+		name     string
+		typeArgs string
+	}{
+		{"Max[int]", "int"},
+		{"Max[float64]", "float64"},
+		{"(*Stack[string]).Push", "string"},
+		{"Pair[string, []int]", "string,[]int"},
+	}
+
+	for _, tt := range tests {
+		fn, err := NewBuilder().ParseAndBuildSSA(genericsSource, tt.name)
+		if err != nil {
+			t.Errorf("ParseAndBuildSSA(%q): %v", tt.name, err)
+			continue
+		}
+		var targs []string
+		for _, targ := range fn.TypeArgs() {
+			targs = append(targs, targ.String())
+		}
+		if got := strings.Join(targs, ","); got != tt.typeArgs {
+			t.Errorf("ParseAndBuildSSA(%q) type args = %s, expected %s", tt.name, got, tt.typeArgs)
+		}
+		if fn.Blocks == nil {
+			t.Errorf("ParseAndBuildSSA(%q): instance body is not built", tt.name)
+		}
+	}
+
+	if _, err := NewBuilder().ParseAndBuildSSA(genericsSource, "Max[[]int]"); err == nil {
+		t.Error("expected error for type argument not satisfying constraint")
+	}
+
+	// Текст имени не должен попадать в синтетический файл без проверки
+	for _, name := range []string{
+		"Max[nosuch]",
+		"Max[1]",
+		"Max[int]}; func init() { panic(0) }; var _ = []interface{}{Max[int]",
+		"Max[int, func() { panic(0) }]",
+	} {
+		var badTarget *BadTargetError
+		if _, err := NewBuilder().ParseAndBuildSSA(genericsSource, name); !errors.As(err, &badTarget) || badTarget.Name != name {
+			t.Errorf("ParseAndBuildSSA(%q): expected BadTargetError, got %v", name, err)
+		}
+	}
+}
+
+func TestLoadAndBuildSSAInstantiatesGenerics(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/generic\n\ngo 1.21\n",
+		"util/util.go": `package util
+
+func min[T int | float64](a, b T) T {
+	if a < b {
+		return a
+	}
+	return b
+}
+`,
+	})
+
+	builder := NewBuilder()
+	builder.Instantiate("util.min[float64]")
+
+	program, err := builder.LoadAndBuildSSA(dir, "./...")
+	if err != nil {
+		t.Fatalf("LoadAndBuildSSA: %v", err)
+	}
+
+	fn, err := program.Function("util.min[float64]")
+	if err != nil {
+		t.Fatalf("Function: %v", err)
+	}
+	if len(fn.TypeArgs()) != 1 || fn.TypeArgs()[0].String() != "float64" || fn.Blocks == nil {
+		t.Errorf("unexpected instance %s", fn)
+	}
+
+	builder = NewBuilder()
+	builder.Instantiate("util.min[nosuch]")
+	var badTarget *BadTargetError
+	if _, err := builder.LoadAndBuildSSA(dir, "./..."); !errors.As(err, &badTarget) {
+		t.Errorf("expected BadTargetError, got %v", err)
+	}
+}
+
+func TestExportFunctionGraph(t *testing.T) {
+	fn, err := NewBuilder().ParseAndBuildSSA(methodsSource, "outer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loop, err := NewBuilder().ParseAndBuildSSA(`package main
+
+func sum(n int) int {
+	s := 0
+	for i := 0; i < n; i++ {
+		s += i
+	}
+	return s
+}
+`, "sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, loop); err != nil {
+		t.Fatal(err)
+	}
+	var graph FunctionGraph
+	if err := json.Unmarshal(buf.Bytes(), &graph); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if graph.Name != "main.sum" || len(graph.Blocks) != len(loop.Blocks) {
+		t.Fatalf("unexpected graph %+v", graph)
+	}
+	if graph.Blocks[0].Idom != -1 {
+		t.Errorf("entry block must have no dominator")
+	}
+	for _, block := range graph.Blocks[1:] {
+		if block.Idom < 0 {
+			t.Errorf("block %d has no dominator", block.Index)
+		}
+		for _, pred := range block.Preds {
+			if !slices.Contains(graph.Blocks[pred].Succs, block.Index) {
+				t.Errorf("edge %d -> %d is not symmetric", pred, block.Index)
+			}
+		}
+	}
+
+	buf.Reset()
+	if err := WriteDOT(&buf, fn); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+	if !strings.HasPrefix(dot, `digraph "main.outer" {`) || !strings.Contains(dot, "test.go:") {
+		t.Errorf("unexpected DOT output:\n%s", dot)
+	}
+}
+
+func TestAnalyseLoops(t *testing.T) {
+	fn, err := NewBuilder().ParseAndBuildSSA(`package main
+
+func nested(n, m int) int {
+	s := 0
+	for i := 0; i < n; i++ {
+		for j := 0; j < m; j++ {
+			if j == i {
+				break
+			}
+			s += j
+		}
+	}
+	for s > 100 {
+		s /= 2
+	}
+	return s
+}
+`, "nested")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := AnalyseLoops(fn)
+	if len(info.Loops) != 3 {
+		t.Fatalf("expected 3 loops, got %d", len(info.Loops))
+	}
+
+	var outer, inner, tail *Loop
+	for _, loop := range info.Loops {
+		switch {
+		case len(loop.Children) == 1:
+			outer = loop
+		case loop.Parent != nil:
+			inner = loop
+		default:
+			tail = loop
+		}
+	}
+	if outer == nil || inner == nil || tail == nil || outer.Children[0] != inner || inner.Parent != outer {
+		t.Fatalf("unexpected loop nesting: %+v", info.Loops)
+	}
+	if outer.Depth != 1 || inner.Depth != 2 || tail.Depth != 1 {
+		t.Errorf("unexpected depths: outer %d, inner %d, tail %d", outer.Depth, inner.Depth, tail.Depth)
+	}
+	if !outer.Contains(inner.Header) || inner.Contains(outer.Header) || tail.Contains(outer.Header) {
+		t.Error("unexpected loop bodies")
+	}
+	if info.LoopOf(inner.Header) != inner || !info.IsHeader(outer.Header) || info.Depth(fn.Blocks[0]) != 0 {
+		t.Error("unexpected block queries")
+	}
+
+	for _, loop := range info.Loops {
+		if len(loop.BackEdges) == 0 {
+			t.Errorf("loop at block %d has no back edges", loop.Header.Index)
+		}
+		for _, edge := range loop.BackEdges {
+			if !info.IsBackEdge(edge.From, edge.To) {
+				t.Errorf("%d -> %d must be a back edge", edge.From.Index, edge.To.Index)
+			}
+		}
+		for _, edge := range loop.Exits {
+			if !info.IsExitEdge(edge.From, edge.To) || loop.Contains(edge.To) {
+				t.Errorf("%d -> %d must be an exit edge", edge.From.Index, edge.To.Index)
+			}
+		}
+	}
+	// break выходит из внутреннего цикла, но не из внешнего
+	if len(inner.Exits) != 2 {
+		t.Errorf("expected 2 exits from inner loop, got %d", len(inner.Exits))
+	}
+}
+
+func TestSourceMap(t *testing.T) {
+	fn, err := NewBuilder().ParseAndBuildSSA(`package main
+
+func clamp(x, limit int) int {
+	result := x * 2
+	if result > limit {
+		return limit
+	}
+	return result
+}
+`, "clamp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sm := NewSourceMap(fn)
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			switch instr := instr.(type) {
+			case *ssa.If:
+				if got := sm.Expr(instr.Cond); got != "result > limit" {
+					t.Errorf("unexpected condition %q", got)
+				}
+				if pos := sm.Position(instr); pos.Filename != "test.go" || pos.Line != 5 {
+					t.Errorf("unexpected if position %s", pos)
+				}
+			case *ssa.BinOp:
+				if instr.Op != token.MUL {
+					continue
+				}
+				if name, ok := sm.Name(instr); !ok || name != "result" {
+					t.Errorf("expected %s to be named result, got %q", instr.Name(), name)
+				}
+				if pos := sm.ValuePosition(instr); pos.Line != 4 {
+					t.Errorf("unexpected value position %s", pos)
+				}
+			}
+		}
+	}
+}
+
+func TestParseAndBuildProgram(t *testing.T) {
+	builder := NewBuilder()
+	builder.Instantiate("Max[int]")
+
+	program, err := builder.ParseAndBuildProgram(genericsSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"Max[int]", "Pair"} {
+		fn, err := program.Function(name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if fn.Prog != program.Prog {
+			t.Errorf("%s belongs to another program", name)
+		}
+	}
+}
+
+func TestParseAndBuildSSAImporters(t *testing.T) {
+	const source = `package main
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+func check(s string, x float64) error {
+	if strings.HasPrefix(s, "-") || math.IsNaN(x) {
+		return errors.New("invalid")
+	}
+	return nil
+}
+`
+
+	for _, mode := range []ImporterMode{ImporterAuto, ImporterGC, ImporterSource, ImporterPackages} {
+		t.Run(mode.String(), func(t *testing.T) {
+			builder := NewBuilder()
+			builder.UseImporter(mode)
+
+			fn, err := builder.ParseAndBuildSSA(source, "check")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fn.Name() != "check" {
+				t.Errorf("unexpected function %s", fn)
+			}
+		})
+	}
+
+	if _, err := ParseImporterMode("cgo"); err == nil {
+		t.Error("expected error for unknown importer")
+	}
+}
+
+func TestProgramFunctions(t *testing.T) {
+	program, err := NewBuilder().ParseAndBuildProgram(methodsSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := func(filter FunctionFilter) []string {
+		var res []string
+		for _, fn := range program.Functions(filter) {
+			res = append(res, FunctionName(fn))
+		}
+		return res
+	}
+
+	all := names(FunctionFilter{})
+	expected := []string{"(*Person).Grow", "Person.String", "outer", "outer$1", "outer$1$1"}
+	if !slices.Equal(all, expected) {
+		t.Errorf("expected %v, got %v", expected, all)
+	}
+	for _, name := range all {
+		if _, err := program.Function(name); err != nil {
+			t.Errorf("listed function %s cannot be resolved: %v", name, err)
+		}
+	}
+
+	for _, fn := range program.Functions(FunctionFilter{Kind: Method}) {
+		if fn.Signature.Recv() == nil {
+			t.Errorf("%s is not a method", fn)
+		}
+	}
+	for _, name := range names(FunctionFilter{Kind: Closure}) {
+		if !strings.Contains(name, "$") {
+			t.Errorf("%s is not a closure", name)
+		}
+	}
+	for _, name := range names(FunctionFilter{ExportedOnly: true}) {
+		if strings.Contains(name, "$") {
+			t.Errorf("closure %s must not be exported", name)
+		}
+	}
+
+	if matched := names(FunctionFilter{Pattern: regexp.MustCompile(`^outer\$`)}); len(matched) != 2 {
+		t.Errorf("unexpected pattern matches %v", matched)
+	}
+}
@@ -0,0 +1,169 @@
+package ssa
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Diagnostic - сообщение об ошибке, привязанное к позиции в исходном коде
+type Diagnostic struct {
+	Pos token.Position
+	Msg string
+}
+
+func (d Diagnostic) String() string {
+	if !d.Pos.IsValid() {
+		return d.Msg
+	}
+	return fmt.Sprintf("%s: %s", d.Pos, d.Msg)
+}
+
+// ParseError сообщает о синтаксических ошибках в исходном коде
+type ParseError struct {
+	Diagnostics []Diagnostic
+}
+
+func (e *ParseError) Error() string {
+	return "parse error: " + joinDiagnostics(e.Diagnostics)
+}
+
+// TypeCheckError сообщает об ошибках проверки типов
+type TypeCheckError struct {
+	Diagnostics []Diagnostic
+}
+
+func (e *TypeCheckError) Error() string {
+	return "type check error: " + joinDiagnostics(e.Diagnostics)
+}
+
+// UnsupportedError сообщает о конструкции, которую анализатор не умеет обрабатывать
+type UnsupportedError struct {
+	Pos       token.Position
+	Construct string
+}
+
+func (e *UnsupportedError) Error() string {
+	if !e.Pos.IsValid() {
+		return "unsupported construct: " + e.Construct
+	}
+	return fmt.Sprintf("%s: unsupported construct: %s", e.Pos, e.Construct)
+}
+
+// NewUnsupportedError создаёт ошибку о неподдерживаемой конструкции в позиции pos
+func NewUnsupportedError(fset *token.FileSet, pos token.Pos, format string, args ...interface{}) *UnsupportedError {
+	err := &UnsupportedError{Construct: fmt.Sprintf(format, args...)}
+	if fset != nil {
+		err.Pos = fset.Position(pos)
+	}
+	return err
+}
+
+// FunctionNotFoundError сообщает, что целевая функция не найдена
+type FunctionNotFoundError struct {
+	Name string
+}
+
+func (e *FunctionNotFoundError) Error() string {
+	return fmt.Sprintf("no such function: %s", e.Name)
+}
+
+// BadTargetError сообщает, что имя экземпляра обобщённой функции не удалось разобрать:
+// оно не ссылается на функцию или метод либо аргумент типа не является типом
+type BadTargetError struct {
+	Name   string
+	Reason string
+}
+
+func (e *BadTargetError) Error() string {
+	return fmt.Sprintf("bad target %s: %s", e.Name, e.Reason)
+}
+
+func joinDiagnostics(diagnostics []Diagnostic) string {
+	lines := make([]string, len(diagnostics))
+	for i, d := range diagnostics {
+		lines[i] = d.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// newParseError преобразует ошибку go/parser в ParseError
+func newParseError(err error) error {
+	list, ok := err.(scanner.ErrorList)
+	if !ok {
+		return &ParseError{Diagnostics: []Diagnostic{{Msg: err.Error()}}}
+	}
+
+	res := &ParseError{}
+	for _, e := range list {
+		res.Diagnostics = append(res.Diagnostics, Diagnostic{Pos: e.Pos, Msg: e.Msg})
+	}
+	return res
+}
+
+// typeCheckDiagnostic преобразует ошибку go/types в Diagnostic
+func typeCheckDiagnostic(err error) Diagnostic {
+	if e, ok := err.(types.Error); ok {
+		return Diagnostic{Pos: e.Fset.Position(e.Pos), Msg: e.Msg}
+	}
+	return Diagnostic{Msg: err.Error()}
+}
+
+// newPackagesError группирует ошибки загрузки go/packages по видам.
+// Синтаксические ошибки имеют приоритет над ошибками типов.
+func newPackagesError(errs []packages.Error) error {
+	parseErr := &ParseError{}
+	typeErr := &TypeCheckError{}
+	var other []string
+
+	for _, e := range errs {
+		d := Diagnostic{Pos: parsePosition(e.Pos), Msg: e.Msg}
+		switch e.Kind {
+		case packages.ParseError:
+			parseErr.Diagnostics = append(parseErr.Diagnostics, d)
+		case packages.TypeError:
+			typeErr.Diagnostics = append(typeErr.Diagnostics, d)
+		default:
+			other = append(other, e.Error())
+		}
+	}
+
+	switch {
+	case len(parseErr.Diagnostics) > 0:
+		return parseErr
+	case len(typeErr.Diagnostics) > 0:
+		return typeErr
+	default:
+		return fmt.Errorf("package loading failed:\n%s", strings.Join(other, "\n"))
+	}
+}
+
+// parsePosition разбирает позицию go/packages вида "file:line:col" или "file:line"
+func parsePosition(pos string) token.Position {
+	rest, last, ok := cutNumber(pos)
+	if !ok {
+		return token.Position{Filename: pos}
+	}
+	if file, line, ok := cutNumber(rest); ok {
+		return token.Position{Filename: file, Line: line, Column: last}
+	}
+	return token.Position{Filename: rest, Line: last}
+}
+
+// cutNumber отделяет от строки числовой суффикс после последнего ':'
+func cutNumber(s string) (string, int, bool) {
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return s, 0, false
+	}
+	n, err := strconv.Atoi(s[i+1:])
+	if err != nil {
+		return s, 0, false
+	}
+	return s[:i], n, true
+}
@@ -0,0 +1,164 @@
+package ssa
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// FunctionGraph - описание графа потока управления SSA функции для экспорта
+type FunctionGraph struct {
+	Name   string       `json:"name"`
+	Pos    string       `json:"pos,omitempty"`
+	Params []string     `json:"params"`
+	Blocks []BlockGraph `json:"blocks"`
+}
+
+// BlockGraph - базовый блок с инструкциями, рёбрами и непосредственным доминатором
+type BlockGraph struct {
+	Index    int     `json:"index"`
+	Comment  string  `json:"comment,omitempty"`
+	Instrs   []Instr `json:"instrs"`
+	Preds    []int   `json:"preds"`
+	Succs    []int   `json:"succs"`
+	Idom     int     `json:"idom"` // -1 для входного блока
+	Dominees []int   `json:"dominees"`
+}
+
+// Instr - инструкция блока в текстовом виде с позицией в исходном коде
+type Instr struct {
+	Text string `json:"text"`
+	Pos  string `json:"pos,omitempty"`
+}
+
+// NewFunctionGraph собирает описание графа функции fn
+func NewFunctionGraph(fn *ssa.Function) *FunctionGraph {
+	fset := fn.Prog.Fset
+
+	graph := &FunctionGraph{
+		Name:   fn.String(),
+		Pos:    position(fset, fn.Pos()),
+		Params: []string{},
+		Blocks: []BlockGraph{},
+	}
+	for _, param := range fn.Params {
+		graph.Params = append(graph.Params, param.Name()+" "+param.Type().String())
+	}
+
+	for _, block := range fn.Blocks {
+		bg := BlockGraph{
+			Index:    block.Index,
+			Comment:  block.Comment,
+			Instrs:   []Instr{},
+			Preds:    blockIndices(block.Preds),
+			Succs:    blockIndices(block.Succs),
+			Idom:     -1,
+			Dominees: blockIndices(block.Dominees()),
+		}
+		if idom := block.Idom(); idom != nil {
+			bg.Idom = idom.Index
+		}
+		for _, instr := range block.Instrs {
+			bg.Instrs = append(bg.Instrs, Instr{
+				Text: instrText(instr),
+				Pos:  position(fset, instr.Pos()),
+			})
+		}
+		graph.Blocks = append(graph.Blocks, bg)
+	}
+
+	return graph
+}
+
+// WriteJSON записывает граф функции в формате JSON
+func WriteJSON(w io.Writer, fn *ssa.Function) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(NewFunctionGraph(fn))
+}
+
+// WriteDOT записывает граф функции в формате Graphviz DOT.
+// Сплошные рёбра - переходы управления (для if подписаны true/false),
+// пунктирные - связь блока с его непосредственным доминатором.
+func WriteDOT(w io.Writer, fn *ssa.Function) error {
+	graph := NewFunctionGraph(fn)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "digraph %q {\n", graph.Name)
+	fmt.Fprintf(&out, "\tlabel=%q;\n", graph.Name+" "+graph.Pos)
+	out.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+
+	for _, block := range graph.Blocks {
+		lines := []string{fmt.Sprintf("%d: %s", block.Index, block.Comment)}
+		for _, instr := range block.Instrs {
+			line := instr.Text
+			if instr.Pos != "" {
+				line += "  // " + instr.Pos
+			}
+			lines = append(lines, line)
+		}
+		fmt.Fprintf(&out, "\tb%d [label=%s];\n", block.Index, dotLabel(lines))
+	}
+
+	for _, block := range graph.Blocks {
+		for i, succ := range block.Succs {
+			label := ""
+			if len(block.Succs) == 2 {
+				label = fmt.Sprintf(" [label=%q]", [2]string{"true", "false"}[i])
+			}
+			fmt.Fprintf(&out, "\tb%d -> b%d%s;\n", block.Index, succ, label)
+		}
+	}
+
+	for _, block := range graph.Blocks {
+		if block.Idom >= 0 {
+			fmt.Fprintf(&out, "\tb%d -> b%d [style=dashed, color=gray, constraint=false];\n", block.Idom, block.Index)
+		}
+	}
+
+	out.WriteString("}\n")
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// dotLabel формирует многострочную метку DOT с выравниванием строк влево
+func dotLabel(lines []string) string {
+	var label strings.Builder
+	label.WriteString("\"")
+	for _, line := range lines {
+		escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(line)
+		label.WriteString(escaped)
+		label.WriteString(`\l`)
+	}
+	label.WriteString("\"")
+	return label.String()
+}
+
+// instrText возвращает текст инструкции, для значений - в виде "t0 = ..."
+func instrText(instr ssa.Instruction) string {
+	if value, ok := instr.(ssa.Value); ok && value.Name() != "" {
+		return value.Name() + " = " + instr.String()
+	}
+	return instr.String()
+}
+
+func blockIndices(blocks []*ssa.BasicBlock) []int {
+	res := make([]int, len(blocks))
+	for i, block := range blocks {
+		res[i] = block.Index
+	}
+	return res
+}
+
+func position(fset *token.FileSet, pos token.Pos) string {
+	if fset == nil || !pos.IsValid() {
+		return ""
+	}
+	return fset.Position(pos).String()
+}
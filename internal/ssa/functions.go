@@ -0,0 +1,156 @@
+package ssa
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// FunctionKind - вид функции по её сигнатуре и способу объявления
+type FunctionKind int
+
+const (
+	// AnyFunction подходит под любой вид
+	AnyFunction FunctionKind = iota
+	// PlainFunction - функция уровня пакета без получателя
+	PlainFunction
+	// Method - метод именованного типа
+	Method
+	// Closure - анонимная функция
+	Closure
+)
+
+func (k FunctionKind) String() string {
+	switch k {
+	case AnyFunction:
+		return "all"
+	case PlainFunction:
+		return "func"
+	case Method:
+		return "method"
+	case Closure:
+		return "closure"
+	default:
+		return fmt.Sprintf("FunctionKind(%d)", int(k))
+	}
+}
+
+// ParseFunctionKind разбирает название вида функции: all, func, method или closure
+func ParseFunctionKind(name string) (FunctionKind, error) {
+	for _, kind := range []FunctionKind{AnyFunction, PlainFunction, Method, Closure} {
+		if kind.String() == name {
+			return kind, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown function kind %q: expected all, func, method or closure", name)
+}
+
+// FunctionFilter отбирает функции для пакетного анализа
+type FunctionFilter struct {
+	// Pattern - регулярное выражение, которому должно соответствовать имя функции (см. FunctionName).
+	// nil означает любое имя.
+	Pattern *regexp.Regexp
+	// ExportedOnly оставляет только экспортируемые функции и методы экспортируемых типов
+	ExportedOnly bool
+	// Kind - вид функции
+	Kind FunctionKind
+}
+
+// Functions перечисляет функции, методы и анонимные функции загруженных пакетов,
+// которые можно анализировать: с телом, объявленные в исходном коде и не обобщённые.
+// Функции упорядочены по позиции в исходном коде.
+func (p *Program) Functions(filter FunctionFilter) []*ssa.Function {
+	var res []*ssa.Function
+	for fn := range ssautil.AllFunctions(p.Prog) {
+		if slices.Contains(p.Packages, fn.Pkg) && isAnalyzable(fn) && filter.matches(fn) {
+			res = append(res, fn)
+		}
+	}
+
+	slices.SortFunc(res, func(a, b *ssa.Function) int {
+		pa, pb := p.Fset.Position(a.Pos()), p.Fset.Position(b.Pos())
+		return cmp.Or(
+			cmp.Compare(pa.Filename, pb.Filename),
+			cmp.Compare(pa.Offset, pb.Offset),
+			cmp.Compare(FunctionName(a), FunctionName(b)),
+		)
+	})
+	return res
+}
+
+// FunctionName возвращает имя функции в форме, которую принимает Program.Function:
+// "Func", "(*T).M", "T.M", "outer$1"
+func FunctionName(fn *ssa.Function) string {
+	if parent := fn.Parent(); parent != nil {
+		return FunctionName(parent) + strings.TrimPrefix(fn.Name(), parent.Name())
+	}
+
+	recv := fn.Signature.Recv()
+	if recv == nil {
+		return fn.Name()
+	}
+
+	if ptr, ok := recv.Type().(*types.Pointer); ok {
+		return fmt.Sprintf("(*%s).%s", receiverName(ptr.Elem()), fn.Name())
+	}
+	return fmt.Sprintf("%s.%s", receiverName(recv.Type()), fn.Name())
+}
+
+// receiverName возвращает имя типа получателя без пакета и параметров типа
+func receiverName(tpe types.Type) string {
+	if named, ok := tpe.(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return tpe.String()
+}
+
+// isAnalyzable отбрасывает синтетические обёртки, инициализаторы пакетов,
+// функции без тела и обобщённые функции без аргументов типа
+func isAnalyzable(fn *ssa.Function) bool {
+	if fn.Synthetic != "" || fn.Blocks == nil || strings.Contains(fn.Name(), "#") {
+		return false
+	}
+
+	top := fn
+	for top.Parent() != nil {
+		top = top.Parent()
+	}
+	return top.TypeParams().Len() == 0 || len(top.TypeArgs()) > 0
+}
+
+func (filter FunctionFilter) matches(fn *ssa.Function) bool {
+	kind := PlainFunction
+	switch {
+	case fn.Parent() != nil:
+		kind = Closure
+	case fn.Signature.Recv() != nil:
+		kind = Method
+	}
+	if filter.Kind != AnyFunction && filter.Kind != kind {
+		return false
+	}
+
+	if filter.ExportedOnly {
+		if kind == Closure || !ast.IsExported(fn.Name()) {
+			return false
+		}
+		if kind == Method {
+			recv := fn.Signature.Recv().Type()
+			if ptr, ok := recv.(*types.Pointer); ok {
+				recv = ptr.Elem()
+			}
+			if !ast.IsExported(receiverName(recv)) {
+				return false
+			}
+		}
+	}
+
+	return filter.Pattern == nil || filter.Pattern.MatchString(FunctionName(fn))
+}
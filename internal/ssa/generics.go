@@ -0,0 +1,177 @@
+package ssa
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// IsInstanceName сообщает, содержит ли имя функции аргументы типа, например "Max[int]"
+func IsInstanceName(name string) bool {
+	_, typeArgs := splitTypeArgs(name)
+	return len(typeArgs) > 0
+}
+
+// splitTypeArgs отделяет от имени целевой функции аргументы типа:
+// "Max[int]" -> "Max", ["int"]; "(*Pair[int, string]).Swap" -> "(*Pair).Swap", ["int", "string"].
+// Для имён без аргументов типа возвращается nil.
+func splitTypeArgs(name string) (string, []string) {
+	start := strings.Index(name, "[")
+	if start < 0 {
+		return name, nil
+	}
+
+	var args []string
+	depth, argStart := 0, start+1
+	for i := start; i < len(name); i++ {
+		switch name[i] {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(name[argStart:i]))
+				return name[:start] + name[i+1:], args
+			}
+		case ',':
+			if depth == 1 {
+				args = append(args, strings.TrimSpace(name[argStart:i]))
+				argStart = i + 1
+			}
+		}
+	}
+
+	return name, nil
+}
+
+// lookupInstance находит построенный экземпляр обобщённой функции origin
+// с аргументами типа typeArgs, записанными в синтаксисе Go
+func lookupInstance(origin *ssa.Function, typeArgs []string) *ssa.Function {
+	if origin.TypeParams().Len() != len(typeArgs) {
+		return nil
+	}
+
+	targs := make([]types.Type, len(typeArgs))
+	for i, arg := range typeArgs {
+		tv, err := types.Eval(origin.Prog.Fset, origin.Pkg.Pkg, token.NoPos, arg)
+		if err != nil || !tv.IsType() {
+			return nil
+		}
+		targs[i] = tv.Type
+	}
+
+	for fn := range ssautil.AllFunctions(origin.Prog) {
+		if fn.Origin() == origin && identicalTypes(fn.TypeArgs(), targs) {
+			return fn
+		}
+	}
+	return nil
+}
+
+func identicalTypes(xs, ys []types.Type) bool {
+	if len(xs) != len(ys) {
+		return false
+	}
+	for i := range xs {
+		if !types.Identical(xs[i], ys[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkInstance проверяет выражение экземпляра expr, полученное из имени name,
+// прежде чем оно попадёт в синтетический файл пакета pkg: без аргументов типа оно
+// должно ссылаться на функцию или метод, а каждый аргумент типа, как и в lookupInstance,
+// должен вычисляться в тип в области видимости пакета. Возвращает выражение,
+// собранное заново из проверенных частей.
+func checkInstance(fset *token.FileSet, pkg *types.Package, name, expr string) (string, error) {
+	base, typeArgs := splitTypeArgs(expr)
+	if !isFunctionRef(base) {
+		return "", &BadTargetError{Name: name, Reason: "expected a generic function or method"}
+	}
+
+	for _, arg := range typeArgs {
+		tv, err := types.Eval(fset, pkg, token.NoPos, arg)
+		if err != nil {
+			return "", &BadTargetError{Name: name, Reason: fmt.Sprintf("type argument %s: %v", arg, err)}
+		}
+		if !tv.IsType() {
+			return "", &BadTargetError{Name: name, Reason: fmt.Sprintf("type argument %s is not a type", arg)}
+		}
+	}
+
+	start := strings.Index(expr, "[")
+	return base[:start] + "[" + strings.Join(typeArgs, ", ") + "]" + base[start:], nil
+}
+
+// isFunctionRef сообщает, является ли expr именем функции "F", "pkg.F" или метода "T.M", "(*T).M"
+func isFunctionRef(expr string) bool {
+	e, err := parser.ParseExpr(expr)
+	if err != nil {
+		return false
+	}
+	if sel, ok := e.(*ast.SelectorExpr); ok {
+		e = sel.X
+		if paren, ok := e.(*ast.ParenExpr); ok {
+			star, ok := paren.X.(*ast.StarExpr)
+			if !ok {
+				return false
+			}
+			e = star.X
+		}
+	}
+	_, ok := e.(*ast.Ident)
+	return ok
+}
+
+// instanceSource возвращает файл пакета pkgName, ссылающийся на экземпляры
+// обобщённых функций exprs. go/ssa строит тела экземпляров только для
+// используемых в программе инстанциаций, поэтому такой файл добавляется к пакету.
+// Ссылка на функцию сама по себе не порождает инструкций, поэтому экземпляры
+// складываются в срез: так они попадают в операнды кода инициализации пакета.
+// Выражения должны быть проверены checkInstance.
+func instanceSource(pkgName string, exprs []string) string {
+	var src strings.Builder
+	fmt.Fprintf(&src, "package %s\n\nvar _ = []interface{}{\n", pkgName)
+	for _, expr := range exprs {
+		fmt.Fprintf(&src, "\t%s,\n", expr)
+	}
+	src.WriteString("}\n")
+	return src.String()
+}
+
+// instanceExpr превращает имя экземпляра в выражение Go внутри пакета:
+// отрезает суффикс анонимной функции и квалификатор пакета.
+// Возвращает false для имён без аргументов типа.
+func instanceExpr(name string, qualifiers ...string) (string, bool) {
+	if _, args := splitTypeArgs(name); args == nil {
+		return "", false
+	}
+
+	expr, _, _ := strings.Cut(name, "$")
+	rest := strings.TrimLeft(expr, "(*")
+	prefix := expr[:len(expr)-len(rest)]
+	for _, qualifier := range qualifiers {
+		if trimmed, ok := strings.CutPrefix(rest, qualifier+"."); ok {
+			return prefix + trimmed, true
+		}
+	}
+	return expr, true
+}
+
+// declName возвращает имя объявления уровня пакета, с которого начинается выражение:
+// "(*Stack[int]).Push" -> "Stack", "Max[int]" -> "Max"
+func declName(expr string) string {
+	expr = strings.TrimLeft(expr, "(*")
+	if i := strings.IndexAny(expr, "[.)"); i >= 0 {
+		return expr[:i]
+	}
+	return expr
+}
@@ -0,0 +1,128 @@
+package ssa
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// ImporterMode определяет, откуда ParseAndBuildSSA берёт информацию о типах
+// импортируемых пакетов. LoadAndBuildSSA всегда использует go/packages.
+type ImporterMode int
+
+const (
+	// ImporterAuto использует экспортные данные компилятора, а если какой-либо
+	// пакет импортировать не удалось - повторяет проверку типов по исходному коду
+	ImporterAuto ImporterMode = iota
+	// ImporterGC читает экспортные данные компилятора gc (importer.Default).
+	// Требует собранных пакетов или доступной команды go.
+	ImporterGC
+	// ImporterSource проверяет типы импортируемых пакетов по их исходному коду из GOROOT и GOPATH.
+	// Медленнее, но работает без экспортных данных и без сети.
+	ImporterSource
+	// ImporterPackages загружает импортируемые пакеты через golang.org/x/tools/go/packages
+	ImporterPackages
+)
+
+func (m ImporterMode) String() string {
+	switch m {
+	case ImporterAuto:
+		return "auto"
+	case ImporterGC:
+		return "gc"
+	case ImporterSource:
+		return "source"
+	case ImporterPackages:
+		return "packages"
+	default:
+		return fmt.Sprintf("ImporterMode(%d)", int(m))
+	}
+}
+
+// ParseImporterMode разбирает название режима импорта: auto, gc, source или packages
+func ParseImporterMode(name string) (ImporterMode, error) {
+	for _, mode := range []ImporterMode{ImporterAuto, ImporterGC, ImporterSource, ImporterPackages} {
+		if mode.String() == name {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown importer %q: expected auto, gc, source or packages", name)
+}
+
+// newImporter создаёт импортёр режима mode для проверки типов файлов files
+func newImporter(mode ImporterMode, fset *token.FileSet, files []*ast.File) (types.Importer, error) {
+	switch mode {
+	case ImporterGC, ImporterAuto:
+		return &trackingImporter{importer: importer.ForCompiler(fset, "gc", nil)}, nil
+	case ImporterSource:
+		return importer.ForCompiler(fset, "source", nil), nil
+	case ImporterPackages:
+		return newPackagesImporter(fset, files)
+	default:
+		return nil, fmt.Errorf("unknown importer mode %s", mode)
+	}
+}
+
+// trackingImporter запоминает, что хотя бы один пакет импортировать не удалось,
+// чтобы режим ImporterAuto мог повторить проверку типов по исходному коду
+type trackingImporter struct {
+	importer types.Importer
+	failed   bool
+}
+
+func (ti *trackingImporter) Import(path string) (*types.Package, error) {
+	pkg, err := ti.importer.Import(path)
+	if err != nil {
+		ti.failed = true
+	}
+	return pkg, err
+}
+
+// packagesImporter отдаёт пакеты, заранее загруженные через go/packages.
+// Все импорты файлов загружаются одним вызовом, чтобы общие зависимости
+// были представлены одними и теми же *types.Package.
+type packagesImporter map[string]*types.Package
+
+func newPackagesImporter(fset *token.FileSet, files []*ast.File) (packagesImporter, error) {
+	var paths []string
+	for _, file := range files {
+		for _, spec := range file.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, path)
+		}
+	}
+
+	res := packagesImporter{}
+	if len(paths) == 0 {
+		return res, nil
+	}
+
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedTypes | packages.NeedImports | packages.NeedDeps,
+		Fset: fset,
+	}
+	pkgs, err := loadPackages(cfg, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		res[pkg.PkgPath] = pkg.Types
+	})
+	return res, nil
+}
+
+func (pi packagesImporter) Import(path string) (*types.Package, error) {
+	if pkg, ok := pi[path]; ok {
+		return pkg, nil
+	}
+	return nil, fmt.Errorf("package %s was not loaded", path)
+}
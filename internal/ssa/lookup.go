@@ -0,0 +1,127 @@
+package ssa
+
+import (
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// lookupFunction ищет функцию в пакете по имени. Поддерживаются имена вида:
+//   - "Func" - функция уровня пакета;
+//   - "(*T).M", "(T).M", "T.M" - методы именованных типов;
+//   - "outer$1", "(*T).M$1$2" - анонимные функции, как их именует go/ssa;
+//   - "Max[int]", "(*Stack[int]).Push" - экземпляры обобщённых функций и методов.
+//
+// Имя может быть квалифицировано именем или путём импорта пакета:
+// "pkg.Func", "(*pkg.T).M", "example.com/mod/pkg.T.M".
+// Если квалификатор не совпадает с пакетом, возвращается nil.
+func lookupFunction(pkg *ssa.Package, name string) *ssa.Function {
+	name, typeArgs := splitTypeArgs(name)
+	base, anon, _ := strings.Cut(name, "$")
+
+	var fn *ssa.Function
+	if strings.HasPrefix(base, "(") {
+		fn = lookupParenMethod(pkg, base)
+	} else {
+		fn = lookupMember(pkg, base)
+	}
+	if fn != nil && typeArgs != nil {
+		fn = lookupInstance(fn, typeArgs)
+	}
+	if fn == nil || anon == "" {
+		return fn
+	}
+
+	// Анонимные функции вложены друг в друга: outer$1$2 объявлена внутри outer$1
+	for _, index := range strings.Split(anon, "$") {
+		fn = lookupAnonFunc(fn, fn.Name()+"$"+index)
+		if fn == nil {
+			return nil
+		}
+	}
+	return fn
+}
+
+// lookupMember разбирает имена "Func", "T.M" и их квалифицированные формы
+func lookupMember(pkg *ssa.Package, name string) *ssa.Function {
+	if rest, ok := trimQualifier(pkg, name); ok {
+		name = rest
+	}
+
+	if typeName, method, ok := strings.Cut(name, "."); ok {
+		return lookupMethod(pkg, typeName, method, false)
+	}
+	return pkg.Func(name)
+}
+
+// lookupParenMethod разбирает имена "(*T).M" и "(T).M"
+func lookupParenMethod(pkg *ssa.Package, name string) *ssa.Function {
+	recv, method, ok := strings.Cut(name[1:], ").")
+	if !ok {
+		return nil
+	}
+
+	pointer := strings.HasPrefix(recv, "*")
+	recv = strings.TrimPrefix(recv, "*")
+	if strings.Contains(recv, ".") {
+		rest, ok := trimQualifier(pkg, recv)
+		if !ok {
+			return nil
+		}
+		recv = rest
+	}
+
+	return lookupMethod(pkg, recv, method, pointer)
+}
+
+// lookupMethod возвращает объявленный метод типа typeName.
+// Для методов, объявленных на значении, форма с указателем также допустима.
+func lookupMethod(pkg *ssa.Package, typeName, method string, pointer bool) *ssa.Function {
+	member := pkg.Type(typeName)
+	if member == nil {
+		return nil
+	}
+
+	var recv types.Type = member.Type()
+	if pointer {
+		recv = types.NewPointer(recv)
+	}
+
+	sel := types.NewMethodSet(recv).Lookup(pkg.Pkg, method)
+	if sel == nil && !pointer {
+		sel = types.NewMethodSet(types.NewPointer(recv)).Lookup(pkg.Pkg, method)
+	}
+	if sel == nil {
+		return nil
+	}
+
+	return pkg.Prog.FuncValue(sel.Obj().(*types.Func))
+}
+
+// lookupAnonFunc ищет анонимную функцию с заданным именем среди вложенных в fn
+func lookupAnonFunc(fn *ssa.Function, name string) *ssa.Function {
+	for _, anon := range fn.AnonFuncs {
+		if anon.Name() == name {
+			return anon
+		}
+	}
+	return nil
+}
+
+// trimQualifier отрезает от имени префикс "pkg." или "path/to/pkg.",
+// если он соответствует пакету pkg
+func trimQualifier(pkg *ssa.Package, name string) (string, bool) {
+	for _, qualifier := range []string{pkg.Pkg.Path(), pkg.Pkg.Name()} {
+		if rest, ok := strings.CutPrefix(name, qualifier+"."); ok {
+			return rest, true
+		}
+	}
+	return name, false
+}
+
+// hasQualifier сообщает, квалифицировано ли имя пакетом pkg
+func hasQualifier(pkg *ssa.Package, name string) bool {
+	_, ok := trimQualifier(pkg, strings.TrimLeft(name, "(*"))
+	return ok
+}
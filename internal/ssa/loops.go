@@ -0,0 +1,157 @@
+package ssa
+
+import (
+	"sort"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// Edge - ребро графа потока управления
+type Edge struct {
+	From *ssa.BasicBlock
+	To   *ssa.BasicBlock
+}
+
+// Loop - естественный цикл: заголовок, доминирующий над всеми блоками цикла,
+// и блоки, из которых заголовок достижим по обратным рёбрам
+type Loop struct {
+	Header *ssa.BasicBlock
+	// Blocks - блоки цикла, включая заголовок и вложенные циклы, по возрастанию индекса
+	Blocks []*ssa.BasicBlock
+	// BackEdges - рёбра из тела цикла в заголовок
+	BackEdges []Edge
+	// Exits - рёбра из блоков цикла в блоки вне цикла
+	Exits []Edge
+	// Parent - ближайший объемлющий цикл, nil для внешних циклов
+	Parent   *Loop
+	Children []*Loop
+	// Depth - глубина вложенности, 1 для внешних циклов
+	Depth int
+
+	contains []bool
+}
+
+// Contains сообщает, принадлежит ли блок циклу
+func (l *Loop) Contains(block *ssa.BasicBlock) bool {
+	return block.Index < len(l.contains) && l.contains[block.Index]
+}
+
+// LoopInfo - структура циклов SSA функции.
+// Нерегулярные циклы (с несколькими входами) не распознаются,
+// так как их заголовок не доминирует над телом.
+type LoopInfo struct {
+	Function *ssa.Function
+	// Loops - все циклы функции; объемлющий цикл всегда предшествует вложенным
+	Loops []*Loop
+
+	innermost []*Loop
+}
+
+// AnalyseLoops находит естественные циклы функции по обратным рёбрам дерева доминаторов
+func AnalyseLoops(fn *ssa.Function) *LoopInfo {
+	info := &LoopInfo{
+		Function:  fn,
+		innermost: make([]*Loop, len(fn.Blocks)),
+	}
+
+	byHeader := map[*ssa.BasicBlock]*Loop{}
+	for _, block := range fn.Blocks {
+		for _, succ := range block.Succs {
+			if !succ.Dominates(block) {
+				continue
+			}
+			loop, ok := byHeader[succ]
+			if !ok {
+				loop = &Loop{Header: succ, contains: make([]bool, len(fn.Blocks))}
+				byHeader[succ] = loop
+				info.Loops = append(info.Loops, loop)
+			}
+			loop.BackEdges = append(loop.BackEdges, Edge{From: block, To: succ})
+			loop.collectBody(block)
+		}
+	}
+
+	for _, loop := range info.Loops {
+		for _, block := range fn.Blocks {
+			if !loop.Contains(block) {
+				continue
+			}
+			loop.Blocks = append(loop.Blocks, block)
+			for _, succ := range block.Succs {
+				if !loop.Contains(succ) {
+					loop.Exits = append(loop.Exits, Edge{From: block, To: succ})
+				}
+			}
+		}
+	}
+
+	// Внешние циклы содержат больше блоков, поэтому после сортировки по убыванию
+	// размера родитель каждого цикла - последний уже обработанный цикл, содержащий его заголовок
+	sort.SliceStable(info.Loops, func(i, j int) bool {
+		return len(info.Loops[i].Blocks) > len(info.Loops[j].Blocks)
+	})
+	for i, loop := range info.Loops {
+		for j := i - 1; j >= 0; j-- {
+			if info.Loops[j].Contains(loop.Header) {
+				loop.Parent = info.Loops[j]
+				break
+			}
+		}
+		loop.Depth = 1
+		if loop.Parent != nil {
+			loop.Parent.Children = append(loop.Parent.Children, loop)
+			loop.Depth = loop.Parent.Depth + 1
+		}
+		for _, block := range loop.Blocks {
+			info.innermost[block.Index] = loop
+		}
+	}
+
+	return info
+}
+
+// collectBody добавляет в цикл блоки, из которых достижим latch, не проходя через заголовок
+func (l *Loop) collectBody(latch *ssa.BasicBlock) {
+	l.contains[l.Header.Index] = true
+	stack := []*ssa.BasicBlock{latch}
+	for len(stack) > 0 {
+		block := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if l.contains[block.Index] {
+			continue
+		}
+		l.contains[block.Index] = true
+		stack = append(stack, block.Preds...)
+	}
+}
+
+// LoopOf возвращает самый внутренний цикл, содержащий блок, или nil
+func (info *LoopInfo) LoopOf(block *ssa.BasicBlock) *Loop {
+	return info.innermost[block.Index]
+}
+
+// Depth возвращает глубину вложенности циклов для блока, 0 вне циклов
+func (info *LoopInfo) Depth(block *ssa.BasicBlock) int {
+	if loop := info.LoopOf(block); loop != nil {
+		return loop.Depth
+	}
+	return 0
+}
+
+// IsHeader сообщает, является ли блок заголовком цикла
+func (info *LoopInfo) IsHeader(block *ssa.BasicBlock) bool {
+	loop := info.LoopOf(block)
+	return loop != nil && loop.Header == block
+}
+
+// IsBackEdge сообщает, является ли ребро from -> to обратным ребром цикла
+func (info *LoopInfo) IsBackEdge(from, to *ssa.BasicBlock) bool {
+	return info.IsHeader(to) && info.LoopOf(to).Contains(from)
+}
+
+// IsExitEdge сообщает, выходит ли ребро from -> to хотя бы из одного цикла.
+// Достаточно проверить самый внутренний цикл: объемлющие содержат все его блоки.
+func (info *LoopInfo) IsExitEdge(from, to *ssa.BasicBlock) bool {
+	loop := info.LoopOf(from)
+	return loop != nil && !loop.Contains(to)
+}
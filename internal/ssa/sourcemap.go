@@ -0,0 +1,113 @@
+package ssa
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// SourceMap сопоставляет SSA значениям и инструкциям функции позиции,
+// имена переменных и текст выражений исходного кода.
+// Опирается на инструкции ssa.DebugRef, которые Builder сохраняет
+// благодаря режиму ssa.GlobalDebug.
+type SourceMap struct {
+	fn    *ssa.Function
+	names map[ssa.Value]string
+	exprs map[ssa.Value]ast.Expr
+}
+
+// NewSourceMap собирает отладочную информацию функции fn
+func NewSourceMap(fn *ssa.Function) *SourceMap {
+	sm := &SourceMap{
+		fn:    fn,
+		names: map[ssa.Value]string{},
+		exprs: map[ssa.Value]ast.Expr{},
+	}
+
+	for _, param := range fn.Params {
+		sm.names[param] = param.Name()
+	}
+	for _, freeVar := range fn.FreeVars {
+		sm.names[freeVar] = freeVar.Name()
+	}
+
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			ref, ok := instr.(*ssa.DebugRef)
+			if !ok || ref.IsAddr {
+				continue
+			}
+			if _, ok := sm.exprs[ref.X]; !ok {
+				sm.exprs[ref.X] = ref.Expr
+			}
+			if _, ok := ref.Object().(*types.Var); ok {
+				if _, named := sm.names[ref.X]; !named {
+					sm.names[ref.X] = ref.Expr.(*ast.Ident).Name
+				}
+			}
+		}
+	}
+
+	return sm
+}
+
+// Name возвращает имя переменной Go, значение которой хранит v
+func (sm *SourceMap) Name(v ssa.Value) (string, bool) {
+	name, ok := sm.names[v]
+	return name, ok
+}
+
+// Expr возвращает текст выражения Go, вычисляющего v, например "x > 10".
+// Для констант возвращается их значение, для прочих значений без выражения
+// в исходном коде - имя SSA регистра.
+func (sm *SourceMap) Expr(v ssa.Value) string {
+	if name, ok := sm.names[v]; ok {
+		return name
+	}
+	if expr, ok := sm.exprs[v]; ok {
+		return types.ExprString(expr)
+	}
+	if c, ok := v.(*ssa.Const); ok && c.Value != nil {
+		return c.Value.String()
+	}
+	return v.Name()
+}
+
+// ValuePosition возвращает позицию выражения, вычисляющего v
+func (sm *SourceMap) ValuePosition(v ssa.Value) token.Position {
+	if expr, ok := sm.exprs[v]; ok {
+		return sm.position(expr.Pos())
+	}
+	return sm.position(v.Pos())
+}
+
+// Position возвращает позицию инструкции. Для инструкций без собственной позиции
+// (if, jump) используется позиция условия или первой инструкции блока с позицией,
+// а в крайнем случае - позиция объявления функции.
+func (sm *SourceMap) Position(instr ssa.Instruction) token.Position {
+	if pos := instr.Pos(); pos.IsValid() {
+		return sm.position(pos)
+	}
+	if ifInstr, ok := instr.(*ssa.If); ok {
+		if pos := sm.ValuePosition(ifInstr.Cond); pos.IsValid() {
+			return pos
+		}
+	}
+	if block := instr.Block(); block != nil {
+		for _, other := range block.Instrs {
+			if pos := other.Pos(); pos.IsValid() {
+				return sm.position(pos)
+			}
+		}
+	}
+	return sm.position(sm.fn.Pos())
+}
+
+func (sm *SourceMap) position(pos token.Pos) token.Position {
+	if !pos.IsValid() {
+		return token.Position{}
+	}
+	return sm.fn.Prog.Fset.Position(pos)
+}
@@ -0,0 +1,66 @@
+package symbolic
+
+import "fmt"
+
+// Conversion представляет преобразование типа T(x) с семантикой Go:
+//   - между целыми типами: расширение знаком или нулём по знаковости исходного типа либо отбрасывание старших бит;
+//   - целое в число с плавающей точкой: округление к ближайшему чётному;
+//   - число с плавающей точкой в целое: отбрасывание дробной части (результат при переполнении не определён);
+//   - между float32 и float64: округление к ближайшему чётному;
+//   - к тому же типу (например, между именованными типами bool): значение не меняется.
+type Conversion struct {
+	Operand SymbolicExpression
+	Target  ExpressionType
+}
+
+// NewConversion создаёт преобразование operand к типу target
+func NewConversion(operand SymbolicExpression, target ExpressionType) *Conversion {
+	res, err := NewConversionChecked(operand, target)
+	if err != nil {
+		panic(err.Error())
+	}
+	return res
+}
+
+// NewConversionChecked создаёт преобразование,
+// возвращая ошибку вместо паники, если типы нельзя преобразовать друг в друга
+func NewConversionChecked(operand SymbolicExpression, target ExpressionType) (*Conversion, error) {
+	if err := checkConversion(operand, target); err != nil {
+		return nil, err
+	}
+	return &Conversion{
+		Operand: operand,
+		Target:  target,
+	}, nil
+}
+
+func checkConversion(operand SymbolicExpression, target ExpressionType) error {
+	if operand == nil {
+		return ErrMissingOperand
+	}
+	source := operand.Type()
+	numeric := func(t ExpressionType) bool { return t.IsInteger() || t.IsFloat() }
+	if source != target && !(numeric(source) && numeric(target)) {
+		return ErrIncompatibleTypes
+	}
+	return nil
+}
+
+// Type возвращает тип результата преобразования
+func (c *Conversion) Type() ExpressionType {
+	return c.Target
+}
+
+// String возвращает строковое представление в синтаксисе Go: "int32(x)".
+// Константа печатается с типом, "uint8(int(300))", чтобы не разбираться как константа типа Target.
+func (c *Conversion) String() string {
+	if text, _, ok := constantText(c.Operand); ok {
+		return fmt.Sprintf("%s(%s(%s))", c.Target, c.Operand.Type(), text)
+	}
+	return fmt.Sprintf("%s(%s)", c.Target, c.Operand)
+}
+
+// Accept реализует Visitor pattern
+func (c *Conversion) Accept(visitor Visitor) interface{} {
+	return visitor.VisitConversion(c)
+}
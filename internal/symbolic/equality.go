@@ -0,0 +1,301 @@
+package symbolic
+
+import (
+	"hash/fnv"
+	"math"
+	"slices"
+)
+
+// Структурное равенство и хеширование выражений.
+// Два выражения равны, если у них одинаковый вид, одинаковые собственные поля
+// (имя, тип, значение, оператор) и попарно равные подвыражения. Константы
+// с плавающей точкой сравниваются побитово: NaN равен NaN с тем же представлением,
+// а 0.0 и -0.0 различаются. Хеш зависит только от структуры выражения
+// и одинаков между запусками программы.
+
+// Вид выражения - первая часть хеша, различающая узлы с одинаковыми полями
+const (
+	kindVariable byte = iota + 1
+	kindInt
+	kindBool
+	kindFloat
+	kindString
+	kindBinary
+	kindUnary
+	kindLogical
+	kindConditional
+	kindSelect
+	kindStore
+	kindApplication
+	kindStringOperation
+	kindConversion
+	kindRef
+)
+
+// hasher накапливает FNV-1a хеш полей узла
+type hasher struct {
+	buf []byte
+}
+
+func (h *hasher) byte(b byte) {
+	h.buf = append(h.buf, b)
+}
+
+func (h *hasher) uint64(v uint64) {
+	for i := 0; i < 8; i++ {
+		h.buf = append(h.buf, byte(v>>(8*i)))
+	}
+}
+
+func (h *hasher) string(s string) {
+	h.uint64(uint64(len(s)))
+	h.buf = append(h.buf, s...)
+}
+
+func (h *hasher) sum() uint64 {
+	f := fnv.New64a()
+	f.Write(h.buf)
+	return f.Sum64()
+}
+
+// hashNode вычисляет хеш узла по его полям и хешам подвыражений, которые возвращает child
+func hashNode(expr SymbolicExpression, child func(SymbolicExpression) uint64) uint64 {
+	var h hasher
+	children := func(exprs ...SymbolicExpression) {
+		for _, e := range exprs {
+			h.uint64(child(e))
+		}
+	}
+
+	switch e := expr.(type) {
+	case *SymbolicVariable:
+		h.byte(kindVariable)
+		h.string(e.Name)
+		h.byte(byte(e.ExprType))
+		h.byte(byte(e.Sort.Index))
+		h.byte(byte(e.Sort.Element))
+	case *IntConstant:
+		h.byte(kindInt)
+		h.byte(byte(e.Type()))
+		h.uint64(uint64(e.Value))
+	case *BoolConstant:
+		h.byte(kindBool)
+		if e.Value {
+			h.byte(1)
+		}
+	case *FloatConstant:
+		h.byte(kindFloat)
+		h.byte(byte(e.Type()))
+		h.uint64(math.Float64bits(e.Value))
+	case *StringConstant:
+		h.byte(kindString)
+		h.string(e.Value)
+	case *BinaryOperation:
+		h.byte(kindBinary)
+		h.byte(byte(e.Operator))
+		children(e.Left, e.Right)
+	case *UnaryOperation:
+		h.byte(kindUnary)
+		h.byte(byte(e.Operator))
+		children(e.Left)
+	case *LogicalOperation:
+		h.byte(kindLogical)
+		h.byte(byte(e.Operator))
+		h.uint64(uint64(len(e.Operands)))
+		children(e.Operands...)
+	case *ConditionalExpression:
+		h.byte(kindConditional)
+		children(e.Condition, e.Then, e.Else)
+	case *ArraySelect:
+		h.byte(kindSelect)
+		children(e.Array, e.Index)
+	case *ArrayStore:
+		h.byte(kindStore)
+		children(e.Array, e.Index, e.Value)
+	case *FunctionApplication:
+		h.byte(kindApplication)
+		h.string(e.Function.Name)
+		h.byte(byte(e.Function.Result))
+		for _, param := range e.Function.Params {
+			h.byte(byte(param))
+		}
+		h.uint64(uint64(len(e.Args)))
+		children(e.Args...)
+	case *StringOperation:
+		h.byte(kindStringOperation)
+		h.byte(byte(e.Operator))
+		h.uint64(uint64(len(e.Operands)))
+		children(e.Operands...)
+	case *Conversion:
+		h.byte(kindConversion)
+		h.byte(byte(e.Target))
+		children(e.Operand)
+	case *Ref:
+		h.byte(kindRef)
+		h.byte(byte(e.Tpe))
+		h.uint64(uint64(e.Ptr))
+	default:
+		panic("unexpected expression")
+	}
+
+	return h.sum()
+}
+
+// equalNode сравнивает собственные поля узлов, а подвыражения - функцией child
+func equalNode(a, b SymbolicExpression, child func(x, y SymbolicExpression) bool) bool {
+	children := func(xs, ys []SymbolicExpression) bool {
+		return slices.EqualFunc(xs, ys, child)
+	}
+
+	switch a := a.(type) {
+	case *SymbolicVariable:
+		b, ok := b.(*SymbolicVariable)
+		return ok && a.Name == b.Name && a.ExprType == b.ExprType && a.Sort == b.Sort
+	case *IntConstant:
+		b, ok := b.(*IntConstant)
+		return ok && a.Type() == b.Type() && a.Value == b.Value
+	case *BoolConstant:
+		b, ok := b.(*BoolConstant)
+		return ok && a.Value == b.Value
+	case *FloatConstant:
+		b, ok := b.(*FloatConstant)
+		return ok && a.Type() == b.Type() && math.Float64bits(a.Value) == math.Float64bits(b.Value)
+	case *StringConstant:
+		b, ok := b.(*StringConstant)
+		return ok && a.Value == b.Value
+	case *BinaryOperation:
+		b, ok := b.(*BinaryOperation)
+		return ok && a.Operator == b.Operator && child(a.Left, b.Left) && child(a.Right, b.Right)
+	case *UnaryOperation:
+		b, ok := b.(*UnaryOperation)
+		return ok && a.Operator == b.Operator && child(a.Left, b.Left)
+	case *LogicalOperation:
+		b, ok := b.(*LogicalOperation)
+		return ok && a.Operator == b.Operator && children(a.Operands, b.Operands)
+	case *ConditionalExpression:
+		b, ok := b.(*ConditionalExpression)
+		return ok && child(a.Condition, b.Condition) && child(a.Then, b.Then) && child(a.Else, b.Else)
+	case *ArraySelect:
+		b, ok := b.(*ArraySelect)
+		return ok && child(a.Array, b.Array) && child(a.Index, b.Index)
+	case *ArrayStore:
+		b, ok := b.(*ArrayStore)
+		return ok && child(a.Array, b.Array) && child(a.Index, b.Index) && child(a.Value, b.Value)
+	case *FunctionApplication:
+		b, ok := b.(*FunctionApplication)
+		return ok && a.Function.Name == b.Function.Name && a.Function.Result == b.Function.Result &&
+			slices.Equal(a.Function.Params, b.Function.Params) && children(a.Args, b.Args)
+	case *StringOperation:
+		b, ok := b.(*StringOperation)
+		return ok && a.Operator == b.Operator && children(a.Operands, b.Operands)
+	case *Conversion:
+		b, ok := b.(*Conversion)
+		return ok && a.Target == b.Target && child(a.Operand, b.Operand)
+	case *Ref:
+		b, ok := b.(*Ref)
+		return ok && a.Tpe == b.Tpe && a.Ptr == b.Ptr
+	}
+	return false
+}
+
+func hashOf(expr SymbolicExpression) uint64 {
+	return expr.Hash()
+}
+
+func equal(a, b SymbolicExpression) bool {
+	return a == b || a.Equal(b)
+}
+
+// Hash возвращает структурный хеш переменной
+func (sv *SymbolicVariable) Hash() uint64 { return hashNode(sv, hashOf) }
+
+// Equal сообщает, совпадают ли имя и тип переменных
+func (sv *SymbolicVariable) Equal(other SymbolicExpression) bool { return equalNode(sv, other, equal) }
+
+// Hash возвращает структурный хеш константы
+func (ic *IntConstant) Hash() uint64 { return hashNode(ic, hashOf) }
+
+// Equal сообщает, совпадают ли тип и значение констант
+func (ic *IntConstant) Equal(other SymbolicExpression) bool { return equalNode(ic, other, equal) }
+
+// Hash возвращает структурный хеш константы
+func (bc *BoolConstant) Hash() uint64 { return hashNode(bc, hashOf) }
+
+// Equal сообщает, совпадают ли значения констант
+func (bc *BoolConstant) Equal(other SymbolicExpression) bool { return equalNode(bc, other, equal) }
+
+// Hash возвращает структурный хеш константы
+func (fc *FloatConstant) Hash() uint64 { return hashNode(fc, hashOf) }
+
+// Equal сообщает, совпадают ли тип и битовое представление констант
+func (fc *FloatConstant) Equal(other SymbolicExpression) bool { return equalNode(fc, other, equal) }
+
+// Hash возвращает структурный хеш константы
+func (sc *StringConstant) Hash() uint64 { return hashNode(sc, hashOf) }
+
+// Equal сообщает, совпадают ли значения констант
+func (sc *StringConstant) Equal(other SymbolicExpression) bool { return equalNode(sc, other, equal) }
+
+// Hash возвращает структурный хеш операции
+func (bo *BinaryOperation) Hash() uint64 { return hashNode(bo, hashOf) }
+
+// Equal сообщает, структурно ли равны операции
+func (bo *BinaryOperation) Equal(other SymbolicExpression) bool { return equalNode(bo, other, equal) }
+
+// Hash возвращает структурный хеш операции
+func (uo *UnaryOperation) Hash() uint64 { return hashNode(uo, hashOf) }
+
+// Equal сообщает, структурно ли равны операции
+func (uo *UnaryOperation) Equal(other SymbolicExpression) bool { return equalNode(uo, other, equal) }
+
+// Hash возвращает структурный хеш операции
+func (lo *LogicalOperation) Hash() uint64 { return hashNode(lo, hashOf) }
+
+// Equal сообщает, структурно ли равны операции
+func (lo *LogicalOperation) Equal(other SymbolicExpression) bool { return equalNode(lo, other, equal) }
+
+// Hash возвращает структурный хеш выражения
+func (ce *ConditionalExpression) Hash() uint64 { return hashNode(ce, hashOf) }
+
+// Equal сообщает, структурно ли равны выражения
+func (ce *ConditionalExpression) Equal(other SymbolicExpression) bool {
+	return equalNode(ce, other, equal)
+}
+
+// Hash возвращает структурный хеш чтения
+func (as *ArraySelect) Hash() uint64 { return hashNode(as, hashOf) }
+
+// Equal сообщает, структурно ли равны чтения
+func (as *ArraySelect) Equal(other SymbolicExpression) bool { return equalNode(as, other, equal) }
+
+// Hash возвращает структурный хеш записи
+func (as *ArrayStore) Hash() uint64 { return hashNode(as, hashOf) }
+
+// Equal сообщает, структурно ли равны записи
+func (as *ArrayStore) Equal(other SymbolicExpression) bool { return equalNode(as, other, equal) }
+
+// Hash возвращает структурный хеш применения
+func (fa *FunctionApplication) Hash() uint64 { return hashNode(fa, hashOf) }
+
+// Equal сообщает, совпадают ли объявления функций и аргументы
+func (fa *FunctionApplication) Equal(other SymbolicExpression) bool {
+	return equalNode(fa, other, equal)
+}
+
+// Hash возвращает структурный хеш операции
+func (so *StringOperation) Hash() uint64 { return hashNode(so, hashOf) }
+
+// Equal сообщает, структурно ли равны операции
+func (so *StringOperation) Equal(other SymbolicExpression) bool { return equalNode(so, other, equal) }
+
+// Hash возвращает структурный хеш преобразования
+func (c *Conversion) Hash() uint64 { return hashNode(c, hashOf) }
+
+// Equal сообщает, структурно ли равны преобразования
+func (c *Conversion) Equal(other SymbolicExpression) bool { return equalNode(c, other, equal) }
+
+// Hash возвращает хеш адреса объекта
+func (ref *Ref) Hash() uint64 { return hashNode(ref, hashOf) }
+
+// Equal сообщает, указывают ли ссылки на один объект
+func (ref *Ref) Equal(other SymbolicExpression) bool { return equalNode(ref, other, equal) }
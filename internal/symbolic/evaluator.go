@@ -0,0 +1,442 @@
+package symbolic
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// Evaluator вычисляет значение выражения при конкретных значениях переменных
+// с семантикой Go: переполнение целых по ширине типа, деление и остаток
+// с округлением к нулю, сдвиги на ширину типа и больше, IEEE 754 для чисел
+// с плавающей точкой. Ситуации, в которых Go паникует (деление на ноль,
+// отрицательный сдвиг, индекс вне строки), возвращаются как ошибки.
+//
+// Значения переменных и результаты представлены так же, как в моделях Z3 транслятора:
+// int64 для знаковых целых, uint64 для беззнаковых, float64 или float32, bool,
+// string, []byte и ArrayValue для массивов. На входе допускаются любые целые типы Go.
+type Evaluator struct {
+	// Vars - значения переменных по имени
+	Vars map[string]interface{}
+	// Functions - реализации неинтерпретируемых функций по имени объявления
+	Functions map[string]func(args ...interface{}) (interface{}, error)
+}
+
+// ArrayValue - конкретное значение символьного массива: явно заданные элементы
+// и значение всех остальных. Ключи и элементы представлены так же, как значения переменных.
+// Если Default равен nil, остальные элементы равны нулевому значению типа элементов.
+type ArrayValue struct {
+	Elements map[interface{}]interface{}
+	Default  interface{}
+}
+
+// MissingVariableError сообщает, что значение переменной не задано
+type MissingVariableError struct {
+	Name string
+}
+
+func (e *MissingVariableError) Error() string {
+	return fmt.Sprintf("variable %s is not assigned", e.Name)
+}
+
+// Evaluate вычисляет выражение при значениях переменных vars
+func Evaluate(expr SymbolicExpression, vars map[string]interface{}) (interface{}, error) {
+	return (&Evaluator{Vars: vars}).Evaluate(expr)
+}
+
+// Evaluate вычисляет выражение и возвращает его значение или первую возникшую ошибку
+func (ev *Evaluator) Evaluate(expr SymbolicExpression) (res interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			evalErr, ok := r.(evaluationError)
+			if !ok {
+				panic(r)
+			}
+			res, err = nil, evalErr.err
+		}
+	}()
+
+	e := evaluator{ev}
+	return toExternal(e.eval(expr)), nil
+}
+
+// evaluationError переносит ошибку вычисления из глубины обхода в Evaluate
+type evaluationError struct {
+	err error
+}
+
+func fail(format string, args ...interface{}) {
+	panic(evaluationError{fmt.Errorf(format, args...)})
+}
+
+// evaluator реализует Visitor. Промежуточные значения - константы (*IntConstant,
+// *FloatConstant, *BoolConstant, *StringConstant), срезы байт и *ArrayValue
+// с внутренним представлением элементов, поэтому операции над ними вычисляются
+// теми же функциями, что и при свёртке констант в Simplify.
+type evaluator struct {
+	*Evaluator
+}
+
+func (e evaluator) eval(expr SymbolicExpression) interface{} {
+	if _, ok := expr.(*Ref); ok {
+		fail("cannot evaluate object reference %s", expr)
+	}
+	return expr.Accept(e)
+}
+
+func (e evaluator) VisitVariable(expr *SymbolicVariable) interface{} {
+	value, ok := e.Vars[expr.Name]
+	if !ok {
+		panic(evaluationError{&MissingVariableError{Name: expr.Name}})
+	}
+
+	res, err := toInternal(value, expr.ExprType, expr.Sort)
+	if err != nil {
+		fail("variable %s: %w", expr.Name, err)
+	}
+	return res
+}
+
+func (e evaluator) VisitIntConstant(expr *IntConstant) interface{} {
+	return expr
+}
+
+func (e evaluator) VisitBoolConstant(expr *BoolConstant) interface{} {
+	return expr
+}
+
+func (e evaluator) VisitFloatConstant(expr *FloatConstant) interface{} {
+	return expr
+}
+
+func (e evaluator) VisitStringConstant(expr *StringConstant) interface{} {
+	return expr
+}
+
+func (e evaluator) VisitBinaryOperation(expr *BinaryOperation) interface{} {
+	left := e.eval(expr.Left).(SymbolicExpression)
+	right := e.eval(expr.Right).(SymbolicExpression)
+
+	// Целый операнд смешанной операции приводится к типу другого операнда
+	if left.Type().IsFloat() && right.Type().IsInteger() {
+		right = convert(right, left.Type())
+	}
+	if left.Type().IsInteger() && right.Type().IsFloat() {
+		left = convert(left, right.Type())
+	}
+
+	res := foldBinary(left, right, expr.Operator)
+	if res == nil {
+		// Паникам Go соответствуют только целочисленные деление на ноль и отрицательный сдвиг,
+		// остальные непосчитанные операции не определены для типов операндов
+		count, ok := right.(*IntConstant)
+		switch {
+		case ok && left.Type().IsInteger() && (expr.Operator == DIV || expr.Operator == MOD) && count.Value == 0:
+			fail("integer divide by zero in %s", expr)
+		case ok && left.Type().IsInteger() && (expr.Operator == SHL || expr.Operator == SHR) && count.Type().IsSigned() && count.Value < 0:
+			fail("negative shift amount in %s", expr)
+		default:
+			fail("unsupported operation %s", expr)
+		}
+	}
+	return res
+}
+
+func (e evaluator) VisitUnaryOperation(expr *UnaryOperation) interface{} {
+	value := e.eval(expr.Left).(*IntConstant)
+	return NewTypedIntConstant(^value.Value, value.Type())
+}
+
+func (e evaluator) VisitLogicalOperation(expr *LogicalOperation) interface{} {
+	operand := func(i int) bool {
+		return e.eval(expr.Operands[i]).(*BoolConstant).Value
+	}
+
+	switch expr.Operator {
+	case NOT:
+		return NewBoolConstant(!operand(0))
+	case IMPLIES:
+		return NewBoolConstant(!operand(0) || operand(1))
+	}
+
+	// Операнды вычисляются слева направо с сокращённым вычислением, как в Go
+	absorbing := expr.Operator == OR
+	for i := range expr.Operands {
+		if operand(i) == absorbing {
+			return NewBoolConstant(absorbing)
+		}
+	}
+	return NewBoolConstant(!absorbing)
+}
+
+func (e evaluator) VisitConditionalExpression(expr *ConditionalExpression) interface{} {
+	if e.eval(expr.Condition).(*BoolConstant).Value {
+		return e.eval(expr.Then)
+	}
+	return e.eval(expr.Else)
+}
+
+func (e evaluator) VisitArraySelect(expr *ArraySelect) interface{} {
+	array := e.eval(expr.Array).(*ArrayValue)
+	if value, ok := array.Elements[toExternal(e.eval(expr.Index))]; ok {
+		return value
+	}
+	return array.Default
+}
+
+func (e evaluator) VisitArrayStore(expr *ArrayStore) interface{} {
+	array := e.eval(expr.Array).(*ArrayValue)
+	res := &ArrayValue{
+		Elements: maps.Clone(array.Elements),
+		Default:  array.Default,
+	}
+	res.Elements[toExternal(e.eval(expr.Index))] = e.eval(expr.Value)
+	return res
+}
+
+func (e evaluator) VisitFunctionApplication(expr *FunctionApplication) interface{} {
+	fn, ok := e.Functions[expr.Function.Name]
+	if !ok {
+		fail("function %s is not defined", expr.Function.Name)
+	}
+
+	args := make([]interface{}, len(expr.Args))
+	for i, arg := range expr.Args {
+		args[i] = toExternal(e.eval(arg))
+	}
+
+	value, err := fn(args...)
+	if err != nil {
+		fail("%s: %w", expr, err)
+	}
+	res, err := toInternal(value, expr.Function.Result, ArraySort{})
+	if err != nil {
+		fail("result of %s: %w", expr, err)
+	}
+	return res
+}
+
+func (e evaluator) VisitStringOperation(expr *StringOperation) interface{} {
+	operands := make([]interface{}, len(expr.Operands))
+	for i, operand := range expr.Operands {
+		operands[i] = e.eval(operand)
+	}
+	index := func(i int) int64 {
+		return operands[i].(*IntConstant).Value
+	}
+
+	switch expr.Operator {
+	case LEN:
+		return NewIntConstant(int64(len(sequence(operands[0]))))
+	case AT:
+		s, i := sequence(operands[0]), index(1)
+		if i < 0 || i >= int64(len(s)) {
+			fail("index out of range [%d] with length %d in %s", i, len(s), expr)
+		}
+		return NewTypedIntConstant(int64(s[i]), ByteType)
+	case SLICE:
+		s, lo, hi := sequence(operands[0]), index(1), index(2)
+		if lo < 0 || hi < lo || hi > int64(len(s)) {
+			fail("slice bounds out of range [%d:%d] with length %d in %s", lo, hi, len(s), expr)
+		}
+		if _, ok := operands[0].([]byte); ok {
+			return []byte(s[lo:hi])
+		}
+		return NewStringConstant(s[lo:hi])
+	case HAS_PREFIX:
+		return NewBoolConstant(strings.HasPrefix(sequence(operands[0]), sequence(operands[1])))
+	case HAS_SUFFIX:
+		return NewBoolConstant(strings.HasSuffix(sequence(operands[0]), sequence(operands[1])))
+	case CONTAINS:
+		return NewBoolConstant(strings.Contains(sequence(operands[0]), sequence(operands[1])))
+	case INDEX:
+		return NewIntConstant(int64(strings.Index(sequence(operands[0]), sequence(operands[1]))))
+	case TO_BYTES:
+		return []byte(sequence(operands[0]))
+	case FROM_BYTES:
+		return NewStringConstant(sequence(operands[0]))
+	}
+	panic("not implemented")
+}
+
+// sequence возвращает содержимое строки или среза байт
+func sequence(value interface{}) string {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value.(*StringConstant).Value
+}
+
+func (e evaluator) VisitConversion(expr *Conversion) interface{} {
+	return convert(e.eval(expr.Operand).(SymbolicExpression), expr.Target)
+}
+
+// convert преобразует константу к типу target так же, как преобразование типа в Go.
+// Для числа с плавающей точкой вне диапазона целевого типа результат, как и в Go,
+// зависит от платформы.
+func convert(value SymbolicExpression, target ExpressionType) SymbolicExpression {
+	switch v := value.(type) {
+	case *IntConstant:
+		if target.IsInteger() {
+			return NewTypedIntConstant(v.Value, target)
+		}
+		// В float32 целое округляется один раз, без промежуточного float64
+		if target == Float32Type {
+			if v.Type().IsSigned() {
+				return NewTypedFloatConstant(float64(float32(v.Value)), target)
+			}
+			return NewTypedFloatConstant(float64(float32(uint64(v.Value))), target)
+		}
+		if v.Type().IsSigned() {
+			return NewTypedFloatConstant(float64(v.Value), target)
+		}
+		return NewTypedFloatConstant(float64(uint64(v.Value)), target)
+	case *FloatConstant:
+		if target.IsFloat() {
+			return NewTypedFloatConstant(v.Value, target)
+		}
+		if target.IsSigned() {
+			return NewTypedIntConstant(int64(v.Value), target)
+		}
+		return NewTypedIntConstant(int64(uint64(v.Value)), target)
+	}
+	return value
+}
+
+// toInternal приводит значение Go к внутреннему представлению значения типа tpe
+func toInternal(value interface{}, tpe ExpressionType, sort ArraySort) (interface{}, error) {
+	switch {
+	case tpe.IsInteger():
+		v, ok := integerValue(value)
+		if !ok {
+			return nil, fmt.Errorf("expected %s, got %T", tpe, value)
+		}
+		return NewTypedIntConstant(v, tpe), nil
+	case tpe.IsFloat():
+		switch v := value.(type) {
+		case float64:
+			return NewTypedFloatConstant(v, tpe), nil
+		case float32:
+			return NewTypedFloatConstant(float64(v), tpe), nil
+		}
+	case tpe == BoolType:
+		if v, ok := value.(bool); ok {
+			return NewBoolConstant(v), nil
+		}
+	case tpe == StringType:
+		if v, ok := value.(string); ok {
+			return NewStringConstant(v), nil
+		}
+	case tpe == BytesType:
+		if v, ok := value.([]byte); ok {
+			return v, nil
+		}
+	case tpe == ArrayType:
+		if v, ok := value.(ArrayValue); ok {
+			value = &v
+		}
+		if v, ok := value.(*ArrayValue); ok {
+			return arrayToInternal(v, sort)
+		}
+	}
+	return nil, fmt.Errorf("expected %s, got %T", tpe, value)
+}
+
+// arrayToInternal приводит ключи массива к представлению значений типа индекса,
+// а элементы - к внутреннему представлению
+func arrayToInternal(array *ArrayValue, sort ArraySort) (*ArrayValue, error) {
+	res := &ArrayValue{Elements: make(map[interface{}]interface{}, len(array.Elements))}
+
+	var err error
+	if array.Default == nil {
+		res.Default = zeroValue(sort.Element)
+	} else if res.Default, err = toInternal(array.Default, sort.Element, ArraySort{}); err != nil {
+		return nil, fmt.Errorf("default element: %w", err)
+	}
+
+	for key, value := range array.Elements {
+		index, err := toInternal(key, sort.Index, ArraySort{})
+		if err != nil {
+			return nil, fmt.Errorf("index %v: %w", key, err)
+		}
+		element, err := toInternal(value, sort.Element, ArraySort{})
+		if err != nil {
+			return nil, fmt.Errorf("element %v: %w", key, err)
+		}
+		res.Elements[toExternal(index)] = element
+	}
+	return res, nil
+}
+
+// zeroValue возвращает нулевое значение скалярного типа во внутреннем представлении
+func zeroValue(tpe ExpressionType) interface{} {
+	switch {
+	case tpe.IsInteger():
+		return NewTypedIntConstant(0, tpe)
+	case tpe.IsFloat():
+		return NewTypedFloatConstant(0, tpe)
+	case tpe == BoolType:
+		return NewBoolConstant(false)
+	case tpe == StringType:
+		return NewStringConstant("")
+	case tpe == BytesType:
+		return []byte(nil)
+	}
+	panic("not implemented")
+}
+
+// integerValue возвращает битовое представление значения любого целого типа Go
+func integerValue(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	case uintptr:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// toExternal приводит внутреннее значение к представлению результата Evaluate
+func toExternal(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *IntConstant:
+		if v.Type().IsSigned() {
+			return v.Value
+		}
+		return uint64(v.Value)
+	case *FloatConstant:
+		if v.Type() == Float32Type {
+			return float32(v.Value)
+		}
+		return v.Value
+	case *BoolConstant:
+		return v.Value
+	case *StringConstant:
+		return v.Value
+	case *ArrayValue:
+		res := &ArrayValue{Elements: make(map[interface{}]interface{}, len(v.Elements)), Default: toExternal(v.Default)}
+		for key, value := range v.Elements {
+			res.Elements[key] = toExternal(value)
+		}
+		return res
+	}
+	return value
+}
@@ -0,0 +1,127 @@
+package symbolic
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	x := NewSymbolicVariable("x", IntType)
+	u := NewSymbolicVariable("u", Uint8Type)
+	i8 := NewSymbolicVariable("i8", Int8Type)
+	f := NewSymbolicVariable("f", FloatType)
+	s := NewSymbolicVariable("s", StringType)
+	a := NewArrayVariable("a", ArraySort{Element: Uint8Type})
+	h := NewFunctionDeclaration("h", IntType, IntType)
+	op := NewBinaryOperation
+	c := NewIntConstant
+
+	vars := map[string]interface{}{
+		"x":  -7,
+		"u":  uint8(200),
+		"i8": int8(-128),
+		"f":  2.75,
+		"s":  "key=value",
+		"a":  ArrayValue{Elements: map[interface{}]interface{}{1: uint8(10)}},
+	}
+	evaluator := &Evaluator{
+		Vars: vars,
+		Functions: map[string]func(args ...interface{}) (interface{}, error){
+			"h": func(args ...interface{}) (interface{}, error) { return args[0].(int64) * 2, nil },
+		},
+	}
+
+	tests := []struct {
+		name     string
+		expr     SymbolicExpression
+		expected interface{}
+	}{
+		{"division toward zero", op(x, c(2), DIV), int64(-3)},
+		{"remainder sign", op(x, c(2), MOD), int64(-1)},
+		{"uint8 wraparound", op(u, NewTypedIntConstant(100, Uint8Type), ADD), uint64(44)},
+		{"int8 min / -1", op(i8, NewTypedIntConstant(-1, Int8Type), DIV), int64(-128)},
+		{"wide shift", op(u, c(8), SHL), uint64(0)},
+		{"arithmetic shift", op(x, NewTypedIntConstant(100, UintType), SHR), int64(-1)},
+		{"bitwise not", NewUnaryOperation(u, BNOT), uint64(55)},
+		{"float arithmetic", op(f, NewFloatConstant(0.25), ADD), 3.0},
+		{"mixed arithmetic", op(x, f, MUL), -19.25},
+		{"NaN comparison", op(op(f, NewFloatConstant(math.Inf(1)), MUL), NewFloatConstant(math.NaN()), EQ), false},
+		{"float truncation", NewConversion(op(f, NewFloatConstant(-1), MUL), IntType), int64(-2)},
+		{"narrowing", NewConversion(x, Uint8Type), uint64(249)},
+		{"float32 rounding", NewConversion(NewFloatConstant(0.1), Float32Type), float32(0.1)},
+		{"int to float32 rounding", NewConversion(c(1<<60+1<<36+1), Float32Type), float32(1<<60 + 1<<36 + 1)},
+		{"uint64 to float32 rounding", NewConversion(NewTypedIntConstant(math.MinInt64+1<<39+1, Uint64Type), Float32Type),
+			float32(uint64(1<<63 + 1<<39 + 1))},
+		{"short-circuit", NewLogicalOperation([]SymbolicExpression{NewBoolConstant(false), op(op(x, c(0), DIV), c(1), EQ)}, AND), false},
+		{"ite", Ite(op(x, c(0), LT), c(1), op(c(1), c(0), DIV)), int64(1)},
+		{"array select", NewArraySelect(a, c(1)), uint64(10)},
+		{"array default", NewArraySelect(a, x), uint64(0)},
+		{"array store", NewArraySelect(NewArrayStore(a, x, u), c(-7)), uint64(200)},
+		{"function", NewFunctionApplication(h, x), int64(-14)},
+		{"string index", NewStringOperation(INDEX, s, NewStringConstant("=")), int64(3)},
+		{"string slice", NewStringOperation(SLICE, s, c(4), NewStringOperation(LEN, s)), "value"},
+		{"string concat", op(s, NewStringConstant("!"), ADD), "key=value!"},
+		{"bytes", NewStringOperation(TO_BYTES, NewStringConstant("ab")), []byte("ab")},
+		{"byte at", NewStringOperation(AT, s, c(0)), uint64('k')},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evaluator.Evaluate(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b, ok := tt.expected.([]byte); ok {
+				if string(got.([]byte)) != string(b) {
+					t.Errorf("%s = %v, expected %v", tt.expr, got, tt.expected)
+				}
+				return
+			}
+			if got != tt.expected {
+				t.Errorf("%s = %v (%T), expected %v (%T)", tt.expr, got, got, tt.expected, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	x := NewSymbolicVariable("x", IntType)
+	vars := map[string]interface{}{"x": 0, "s": "abc", "b": 1}
+
+	f := NewSymbolicVariable("f", FloatType)
+	vars["f"] = 1.5
+
+	tests := []struct {
+		name    string
+		expr    SymbolicExpression
+		message string
+	}{
+		{"missing variable", NewSymbolicVariable("y", IntType), "not assigned"},
+		{"wrong value type", NewSymbolicVariable("b", BoolType), ""},
+		{"division by zero", NewBinaryOperation(NewIntConstant(1), x, DIV), "integer divide by zero"},
+		{"negative shift", NewBinaryOperation(NewIntConstant(1), NewIntConstant(-1), SHL), "negative shift amount"},
+		{"index out of range", NewStringOperation(AT, NewSymbolicVariable("s", StringType), NewIntConstant(3)), ""},
+		{"undefined function", NewFunctionApplication(NewFunctionDeclaration("h", IntType, IntType), x), ""},
+		// Операции, не определённые для типов операндов, строятся в обход проверок конструкторов
+		{"float remainder", &BinaryOperation{Left: f, Right: NewFloatConstant(2), Operator: MOD}, "unsupported operation"},
+		{"float shift", &BinaryOperation{Left: f, Right: NewIntConstant(1), Operator: SHL}, "unsupported operation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Evaluate(tt.expr, vars)
+			if err == nil {
+				t.Fatalf("expected error evaluating %s", tt.expr)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("expected %q in error, got %v", tt.message, err)
+			}
+		})
+	}
+
+	_, err := Evaluate(NewBinaryOperation(x, NewSymbolicVariable("y", IntType), ADD), vars)
+	if missing, ok := err.(*MissingVariableError); !ok || missing.Name != "y" {
+		t.Errorf("expected MissingVariableError for y, got %v", err)
+	}
+}
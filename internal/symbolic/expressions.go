@@ -3,6 +3,7 @@ package symbolic
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -16,12 +17,20 @@ type SymbolicExpression interface {
 
 	// Accept принимает visitor для обхода дерева выражений
 	Accept(visitor Visitor) interface{}
+
+	// Equal сообщает, равно ли выражение other структурно (см. equality.go)
+	Equal(other SymbolicExpression) bool
+
+	// Hash возвращает структурный хеш выражения: у равных выражений хеши равны
+	Hash() uint64
 }
 
 // SymbolicVariable представляет символьную переменную
 type SymbolicVariable struct {
 	Name     string
 	ExprType ExpressionType
+	// Sort - типы индексов и элементов для переменных типа ArrayType
+	Sort ArraySort
 }
 
 // NewSymbolicVariable создаёт новую символьную переменную
@@ -32,6 +41,16 @@ func NewSymbolicVariable(name string, exprType ExpressionType) *SymbolicVariable
 	}
 }
 
+// NewArrayVariable создаёт символьный массив с заданными типами индексов и элементов.
+// Все элементы массива изначально неизвестны.
+func NewArrayVariable(name string, sort ArraySort) *SymbolicVariable {
+	return &SymbolicVariable{
+		Name:     name,
+		ExprType: ArrayType,
+		Sort:     sort,
+	}
+}
+
 // Type возвращает тип переменной
 func (sv *SymbolicVariable) Type() ExpressionType {
 	return sv.ExprType
@@ -47,24 +66,45 @@ func (sv *SymbolicVariable) Accept(visitor Visitor) interface{} {
 	return visitor.VisitVariable(sv)
 }
 
-// IntConstant представляет целочисленную константу
+// IntConstant представляет целочисленную константу.
+// Нулевое значение ExprType соответствует типу int.
 type IntConstant struct {
-	Value int64
+	Value    int64
+	ExprType ExpressionType
 }
 
-// NewIntConstant создаёт новую целочисленную константу
+// NewIntConstant создаёт новую целочисленную константу типа int
 func NewIntConstant(value int64) *IntConstant {
 	return &IntConstant{Value: value}
 }
 
+// NewTypedIntConstant создаёт целочисленную константу заданного типа,
+// приводя значение к его ширине (см. ExpressionType.Wrap)
+func NewTypedIntConstant(value int64, exprType ExpressionType) *IntConstant {
+	res, err := NewTypedIntConstantChecked(value, exprType)
+	if err != nil {
+		panic(err.Error())
+	}
+	return res
+}
+
+// NewTypedIntConstantChecked создаёт целочисленную константу заданного типа,
+// возвращая ошибку вместо паники, если тип не целочисленный
+func NewTypedIntConstantChecked(value int64, exprType ExpressionType) (*IntConstant, error) {
+	if !exprType.IsInteger() {
+		return nil, ErrIncorrectType
+	}
+	return &IntConstant{Value: exprType.Wrap(value), ExprType: exprType}, nil
+}
+
 // Type возвращает тип константы
 func (ic *IntConstant) Type() ExpressionType {
-	return IntType
+	return ic.ExprType
 }
 
-// String возвращает строковое представление константы
+// String возвращает строковое представление константы: "5" для int, "uint8(5)" для других типов
 func (ic *IntConstant) String() string {
-	return fmt.Sprintf("%d", ic.Value)
+	return operandString(ic, nil)
 }
 
 // Accept реализует Visitor pattern
@@ -97,23 +137,98 @@ func (bc *BoolConstant) Accept(visitor Visitor) interface{} {
 	return visitor.VisitBoolConstant(bc)
 }
 
+// FloatConstant представляет константу с плавающей точкой.
+// ExprType равен FloatType (float64) или Float32Type.
 type FloatConstant struct {
-	Value float64
+	Value    float64
+	ExprType ExpressionType
 }
 
-// NewBoolConstant создаёт новую булеву константу
+// NewFloatConstant создаёт новую константу типа float64
 func NewFloatConstant(value float64) *FloatConstant {
-	return &FloatConstant{Value: value}
+	return &FloatConstant{Value: value, ExprType: FloatType}
+}
+
+// NewTypedFloatConstant создаёт константу заданного типа с плавающей точкой.
+// Для float32 значение округляется до ближайшего представимого, как при преобразовании в Go.
+func NewTypedFloatConstant(value float64, exprType ExpressionType) *FloatConstant {
+	res, err := NewTypedFloatConstantChecked(value, exprType)
+	if err != nil {
+		panic(err.Error())
+	}
+	return res
+}
+
+// NewTypedFloatConstantChecked создаёт константу с плавающей точкой заданного типа,
+// возвращая ошибку вместо паники, если тип не FloatType и не Float32Type
+func NewTypedFloatConstantChecked(value float64, exprType ExpressionType) (*FloatConstant, error) {
+	switch exprType {
+	case FloatType:
+	case Float32Type:
+		value = float64(float32(value))
+	default:
+		return nil, ErrIncorrectType
+	}
+	return &FloatConstant{Value: value, ExprType: exprType}, nil
 }
 
 // Type возвращает тип константы
 func (fc *FloatConstant) Type() ExpressionType {
+	if fc.ExprType == Float32Type {
+		return Float32Type
+	}
 	return FloatType
 }
 
-// String возвращает строковое представление константы
+// String возвращает строковое представление константы: кратчайшую запись,
+// которая разбирается в то же значение, и "float32(v)" для Float32Type
 func (fc *FloatConstant) String() string {
-	return fmt.Sprintf("%f", fc.Value)
+	return operandString(fc, nil)
+}
+
+// constantText возвращает запись числовой константы без типа и признак дробного литерала;
+// ok равен false, если expr - не числовая константа
+func constantText(expr SymbolicExpression) (text string, float bool, ok bool) {
+	switch c := expr.(type) {
+	case *IntConstant:
+		if !c.ExprType.IsSigned() {
+			return strconv.FormatUint(uint64(c.Value), 10), false, true
+		}
+		return strconv.FormatInt(c.Value, 10), false, true
+	case *FloatConstant:
+		bits := 64
+		if c.Type() == Float32Type {
+			bits = 32
+		}
+		text = strconv.FormatFloat(c.Value, 'g', -1, bits)
+		return text, strings.ContainsAny(text, ".eIN"), true
+	}
+	return "", false, false
+}
+
+// operandString печатает операнд в контексте типа context (nil - без контекста).
+// Константа печатается литералом, если Parser выведет её тип из контекста, иначе - в виде T(v).
+func operandString(expr SymbolicExpression, context *ExpressionType) string {
+	text, float, ok := constantText(expr)
+	if !ok {
+		return expr.String()
+	}
+	if literalType(float, context) == expr.Type() {
+		return text
+	}
+	return fmt.Sprintf("%s(%s)", expr.Type(), text)
+}
+
+// operandStrings печатает операнды, тип которых согласуется, как в Parser.unify:
+// константа рядом с выражением получает его тип, а вторая из двух констант - тип первой
+func operandStrings(a, b SymbolicExpression) (string, string) {
+	_, _, aConst := constantText(a)
+	if _, _, bConst := constantText(b); aConst && !bConst {
+		tpe := b.Type()
+		return operandString(a, &tpe), b.String()
+	}
+	tpe := a.Type()
+	return operandString(a, nil), operandString(b, &tpe)
 }
 
 // Accept реализует Visitor pattern
@@ -130,46 +245,66 @@ type BinaryOperation struct {
 
 // NewBinaryOperation создаёт новую бинарную операцию
 func NewBinaryOperation(left, right SymbolicExpression, op BinaryOperator) *BinaryOperation {
-	if left.Type() == BoolType && right.Type() == BoolType {
-		return &BinaryOperation{
-			Left:     left,
-			Right:    right,
-			Operator: op,
-		}
+	res, err := NewBinaryOperationChecked(left, right, op)
+	if err != nil {
+		panic(err.Error())
 	}
+	return res
+}
 
-	if left.Type() == IntType && right.Type() == IntType {
-		return &BinaryOperation{
-			Left:     left,
-			Right:    right,
-			Operator: op,
-		}
+// NewBinaryOperationChecked создаёт бинарную операцию,
+// возвращая ошибку вместо паники при несовместимых операндах
+func NewBinaryOperationChecked(left, right SymbolicExpression, op BinaryOperator) (*BinaryOperation, error) {
+	if err := checkBinaryOperation(left, right, op); err != nil {
+		return nil, err
 	}
+	return &BinaryOperation{
+		Left:     left,
+		Right:    right,
+		Operator: op,
+	}, nil
+}
 
-	if left.Type() == FloatType && right.Type() == FloatType {
-		return &BinaryOperation{
-			Left:     left,
-			Right:    right,
-			Operator: op,
-		}
+func checkBinaryOperation(left, right SymbolicExpression, op BinaryOperator) error {
+	if left == nil || right == nil {
+		return ErrMissingOperand
 	}
-
-	if left.Type() == IntType && right.Type() == FloatType || left.Type() == FloatType && right.Type() == IntType {
-		return &BinaryOperation{
-			Left:     left,
-			Right:    right,
-			Operator: op,
-		}
+	if op < ADD || op > SHR {
+		return ErrUnknownOperator
 	}
 
-	panic("incompatible types")
+	// Набор допустимых операторов зависит от типа операндов, как в Go:
+	// булевы значения только сравниваются на равенство, строки ещё складываются
+	// и упорядочиваются, а остаток, побитовые операции и сдвиги определены только для целых
+	equality := op == EQ || op == NE
+	comparison := op >= EQ && op <= GE
+	arithmetic := comparison || op >= ADD && op <= DIV
+
+	l, r := left.Type(), right.Type()
+	switch {
+	case l == BoolType && r == BoolType && equality:
+	// Целочисленные операнды должны иметь один тип, кроме счётчика сдвига,
+	// который в Go может быть любого целочисленного типа
+	case l.IsInteger() && r.IsInteger() && (l == r || op == SHL || op == SHR):
+	case l == StringType && r == StringType && (comparison || op == ADD):
+	case l.IsFloat() && l == r && arithmetic:
+	case (l.IsInteger() && r.IsFloat() || l.IsFloat() && r.IsInteger()) && arithmetic:
+	default:
+		return ErrIncompatibleTypes
+	}
+	return nil
 }
 
-// Type возвращает результирующий тип операции
+// Type возвращает результирующий тип операции.
+// Арифметические и побитовые операции имеют тип левого операнда,
+// а при смешении целого и числа с плавающей точкой - тип последнего.
 func (bo *BinaryOperation) Type() ExpressionType {
 	switch bo.Operator {
 	case ADD, SUB, MUL, DIV, MOD, BAND, BOR, BXOR, SHL, SHR:
-		return IntType
+		if bo.Right.Type().IsFloat() && !bo.Left.Type().IsFloat() {
+			return bo.Right.Type()
+		}
+		return bo.Left.Type()
 	case EQ, NE, GT, LT, GE, LE:
 		return BoolType
 	}
@@ -178,7 +313,11 @@ func (bo *BinaryOperation) Type() ExpressionType {
 
 // String возвращает строковое представление операции
 func (bo *BinaryOperation) String() string {
-	return fmt.Sprintf("(%s %s %s)", bo.Left.String(), bo.Operator.String(), bo.Right.String())
+	if bo.Operator == SHL || bo.Operator == SHR {
+		return fmt.Sprintf("(%s %s %s)", operandString(bo.Left, nil), bo.Operator, operandString(bo.Right, nil))
+	}
+	left, right := operandStrings(bo.Left, bo.Right)
+	return fmt.Sprintf("(%s %s %s)", left, bo.Operator.String(), right)
 }
 
 // Accept реализует Visitor pattern
@@ -194,29 +333,51 @@ type LogicalOperation struct {
 
 // NewLogicalOperation создаёт новую логическую операцию
 func NewLogicalOperation(operands []SymbolicExpression, op LogicalOperator) *LogicalOperation {
+	res, err := NewLogicalOperationChecked(operands, op)
+	if err != nil {
+		panic(err.Error())
+	}
+	return res
+}
+
+// NewLogicalOperationChecked создаёт логическую операцию,
+// возвращая ошибку вместо паники при неверном числе или типе операндов
+func NewLogicalOperationChecked(operands []SymbolicExpression, op LogicalOperator) (*LogicalOperation, error) {
+	if err := checkLogicalOperation(operands, op); err != nil {
+		return nil, err
+	}
+	return &LogicalOperation{
+		Operands: operands,
+		Operator: op,
+	}, nil
+}
+
+func checkLogicalOperation(operands []SymbolicExpression, op LogicalOperator) error {
 	switch op {
 	case AND, OR:
 		if len(operands) < 2 {
-			panic("incorrect number of arguments")
+			return ErrArgumentCount
 		}
 	case IMPLIES:
 		if len(operands) != 2 {
-			panic("incorrect number of arguments")
+			return ErrArgumentCount
 		}
 	case NOT:
 		if len(operands) != 1 {
-			panic("incorrect number of arguments")
+			return ErrArgumentCount
 		}
+	default:
+		return ErrUnknownOperator
 	}
-	for _, op := range operands {
-		if op.Type() != BoolType {
-			panic("incorrect type")
+	for _, operand := range operands {
+		if operand == nil {
+			return ErrMissingOperand
+		}
+		if operand.Type() != BoolType {
+			return ErrIncorrectType
 		}
 	}
-	return &LogicalOperation{
-		Operands: operands,
-		Operator: op,
-	}
+	return nil
 }
 
 // Type возвращает тип логической операции (всегда bool)
@@ -301,6 +462,16 @@ func (op BinaryOperator) String() string {
 		return ">"
 	case GE:
 		return ">="
+	case BAND:
+		return "&"
+	case BOR:
+		return "|"
+	case BXOR:
+		return "^"
+	case SHL:
+		return "<<"
+	case SHR:
+		return ">>"
 	default:
 		return "unknown"
 	}
@@ -373,24 +544,46 @@ type UnaryOperation struct {
 }
 
 func NewUnaryOperation(left SymbolicExpression, op UnaryOperator) *UnaryOperation {
-	if left.Type() != IntType {
-		panic("incompatible types")
+	res, err := NewUnaryOperationChecked(left, op)
+	if err != nil {
+		panic(err.Error())
 	}
+	return res
+}
 
+// NewUnaryOperationChecked создаёт унарную операцию,
+// возвращая ошибку вместо паники при нецелочисленном операнде
+func NewUnaryOperationChecked(left SymbolicExpression, op UnaryOperator) (*UnaryOperation, error) {
+	if err := checkUnaryOperation(left, op); err != nil {
+		return nil, err
+	}
 	return &UnaryOperation{
 		Left:     left,
 		Operator: op,
+	}, nil
+}
+
+func checkUnaryOperation(left SymbolicExpression, op UnaryOperator) error {
+	if left == nil {
+		return ErrMissingOperand
 	}
+	if op != BNOT {
+		return ErrUnknownOperator
+	}
+	if !left.Type().IsInteger() {
+		return ErrIncompatibleTypes
+	}
+	return nil
 }
 
 // Type возвращает результирующий тип операции
 func (uo *UnaryOperation) Type() ExpressionType {
-	return IntType
+	return uo.Left.Type()
 }
 
 // String возвращает строковое представление операции
 func (uo *UnaryOperation) String() string {
-	return fmt.Sprintf("(%s %s)", uo.Operator.String(), uo.Left.String())
+	return fmt.Sprintf("(%s %s)", uo.Operator.String(), operandString(uo.Left, nil))
 }
 
 // Accept реализует Visitor pattern
@@ -398,7 +591,343 @@ func (uo *UnaryOperation) Accept(visitor Visitor) interface{} {
 	return visitor.VisitUnaryOperation(uo)
 }
 
-// TODO: Добавьте дополнительные типы выражений по необходимости:
-// - ArrayAccess (доступ к элементам массива: arr[index])
-// - FunctionCall (вызовы функций: f(x, y))
-// - ConditionalExpression (тернарный оператор: condition ? true_expr : false_expr)
+// ConditionalExpression представляет условное выражение: cond ? Then : Else.
+// Позволяет компактно выразить значение, зависящее от выбранной ветви (слияние путей, min, max, abs).
+type ConditionalExpression struct {
+	Condition SymbolicExpression
+	Then      SymbolicExpression
+	Else      SymbolicExpression
+}
+
+// NewConditionalExpression создаёт условное выражение без упрощений.
+// Условие должно быть булевым, а ветви - одного типа.
+func NewConditionalExpression(cond, then, els SymbolicExpression) *ConditionalExpression {
+	res, err := NewConditionalExpressionChecked(cond, then, els)
+	if err != nil {
+		panic(err.Error())
+	}
+	return res
+}
+
+// NewConditionalExpressionChecked создаёт условное выражение,
+// возвращая ошибку вместо паники при небулевом условии или разных типах ветвей
+func NewConditionalExpressionChecked(cond, then, els SymbolicExpression) (*ConditionalExpression, error) {
+	if err := checkConditionalExpression(cond, then, els); err != nil {
+		return nil, err
+	}
+	return &ConditionalExpression{
+		Condition: cond,
+		Then:      then,
+		Else:      els,
+	}, nil
+}
+
+func checkConditionalExpression(cond, then, els SymbolicExpression) error {
+	if cond == nil || then == nil || els == nil {
+		return ErrMissingOperand
+	}
+	if cond.Type() != BoolType || then.Type() != els.Type() ||
+		then.Type() == ArrayType && ArraySortOf(then) != ArraySortOf(els) {
+		return ErrIncompatibleTypes
+	}
+	return nil
+}
+
+// Ite создаёт условное выражение, сразу применяя простые правила упрощения:
+//   - true ? a : b -> a, false ? a : b -> b;
+//   - c ? a : a -> a;
+//   - c ? true : false -> c, c ? false : true -> !c;
+//   - !c ? a : b -> c ? b : a.
+func Ite(cond, then, els SymbolicExpression) SymbolicExpression {
+	if c, ok := cond.(*BoolConstant); ok {
+		if c.Value {
+			return then
+		}
+		return els
+	}
+	if then.Equal(els) {
+		return then
+	}
+
+	thenConst, thenOk := then.(*BoolConstant)
+	elseConst, elseOk := els.(*BoolConstant)
+	if thenOk && elseOk && thenConst.Value != elseConst.Value {
+		if thenConst.Value {
+			return cond
+		}
+		return NewLogicalOperation([]SymbolicExpression{cond}, NOT)
+	}
+
+	if not, ok := cond.(*LogicalOperation); ok && not.Operator == NOT {
+		return NewConditionalExpression(not.Operands[0], els, then)
+	}
+
+	return NewConditionalExpression(cond, then, els)
+}
+
+// Type возвращает тип ветвей
+func (ce *ConditionalExpression) Type() ExpressionType {
+	return ce.Then.Type()
+}
+
+// String возвращает строковое представление выражения
+func (ce *ConditionalExpression) String() string {
+	then, els := operandStrings(ce.Then, ce.Else)
+	return fmt.Sprintf("(%s ? %s : %s)", ce.Condition.String(), then, els)
+}
+
+// Accept реализует Visitor pattern
+func (ce *ConditionalExpression) Accept(visitor Visitor) interface{} {
+	return visitor.VisitConditionalExpression(ce)
+}
+
+// ArraySelect представляет чтение элемента массива: Array[Index]
+type ArraySelect struct {
+	Array SymbolicExpression
+	Index SymbolicExpression
+}
+
+// NewArraySelect создаёт чтение элемента массива.
+// Тип индекса должен совпадать с типом индексов массива.
+func NewArraySelect(array, index SymbolicExpression) *ArraySelect {
+	res, err := NewArraySelectChecked(array, index)
+	if err != nil {
+		panic(err.Error())
+	}
+	return res
+}
+
+// NewArraySelectChecked создаёт чтение элемента массива,
+// возвращая ошибку вместо паники при несовпадении типов
+func NewArraySelectChecked(array, index SymbolicExpression) (*ArraySelect, error) {
+	if err := checkArraySelect(array, index); err != nil {
+		return nil, err
+	}
+	return &ArraySelect{
+		Array: array,
+		Index: index,
+	}, nil
+}
+
+func checkArraySelect(array, index SymbolicExpression) error {
+	if array == nil || index == nil {
+		return ErrMissingOperand
+	}
+	if array.Type() != ArrayType || index.Type() != ArraySortOf(array).Index {
+		return ErrIncompatibleTypes
+	}
+	return nil
+}
+
+// Type возвращает тип элементов массива
+func (as *ArraySelect) Type() ExpressionType {
+	return ArraySortOf(as.Array).Element
+}
+
+// String возвращает строковое представление чтения
+func (as *ArraySelect) String() string {
+	sort := ArraySortOf(as.Array)
+	return fmt.Sprintf("%s[%s]", as.Array.String(), operandString(as.Index, &sort.Index))
+}
+
+// Accept реализует Visitor pattern
+func (as *ArraySelect) Accept(visitor Visitor) interface{} {
+	return visitor.VisitArraySelect(as)
+}
+
+// ArrayStore представляет массив, полученный записью Value по индексу Index.
+// Исходный массив не изменяется.
+type ArrayStore struct {
+	Array SymbolicExpression
+	Index SymbolicExpression
+	Value SymbolicExpression
+}
+
+// NewArrayStore создаёт запись в массив.
+// Типы индекса и значения должны совпадать с сортом массива.
+func NewArrayStore(array, index, value SymbolicExpression) *ArrayStore {
+	res, err := NewArrayStoreChecked(array, index, value)
+	if err != nil {
+		panic(err.Error())
+	}
+	return res
+}
+
+// NewArrayStoreChecked создаёт запись в массив,
+// возвращая ошибку вместо паники при несовпадении типов
+func NewArrayStoreChecked(array, index, value SymbolicExpression) (*ArrayStore, error) {
+	if err := checkArrayStore(array, index, value); err != nil {
+		return nil, err
+	}
+	return &ArrayStore{
+		Array: array,
+		Index: index,
+		Value: value,
+	}, nil
+}
+
+func checkArrayStore(array, index, value SymbolicExpression) error {
+	if array == nil || index == nil || value == nil {
+		return ErrMissingOperand
+	}
+	sort := ArraySortOf(array)
+	if array.Type() != ArrayType || index.Type() != sort.Index || value.Type() != sort.Element {
+		return ErrIncompatibleTypes
+	}
+	return nil
+}
+
+// Type возвращает тип массива
+func (as *ArrayStore) Type() ExpressionType {
+	return ArrayType
+}
+
+// String возвращает строковое представление записи
+func (as *ArrayStore) String() string {
+	sort := ArraySortOf(as.Array)
+	return fmt.Sprintf("%s[%s := %s]", as.Array.String(),
+		operandString(as.Index, &sort.Index), operandString(as.Value, &sort.Element))
+}
+
+// Accept реализует Visitor pattern
+func (as *ArrayStore) Accept(visitor Visitor) interface{} {
+	return visitor.VisitArrayStore(as)
+}
+
+// ArraySortOf возвращает типы индексов и элементов выражения типа ArrayType
+func ArraySortOf(expr SymbolicExpression) ArraySort {
+	switch expr := expr.(type) {
+	case *SymbolicVariable:
+		return expr.Sort
+	case *ArrayStore:
+		return ArraySortOf(expr.Array)
+	case *ConditionalExpression:
+		return ArraySortOf(expr.Then)
+	default:
+		return ArraySort{}
+	}
+}
+
+// FunctionDeclaration объявляет неинтерпретируемую функцию с типизированными
+// параметрами и результатом. О функции известно только, что на равных
+// аргументах она даёт равные результаты.
+type FunctionDeclaration struct {
+	Name   string
+	Params []ExpressionType
+	Result ExpressionType
+}
+
+// NewFunctionDeclaration создаёт объявление неинтерпретируемой функции.
+// Параметры и результат должны быть скалярными (не массивами и не объектами).
+func NewFunctionDeclaration(name string, result ExpressionType, params ...ExpressionType) *FunctionDeclaration {
+	res, err := NewFunctionDeclarationChecked(name, result, params...)
+	if err != nil {
+		panic(err.Error())
+	}
+	return res
+}
+
+// NewFunctionDeclarationChecked создаёт объявление функции,
+// возвращая ошибку вместо паники при нескалярных параметрах или результате
+func NewFunctionDeclarationChecked(name string, result ExpressionType, params ...ExpressionType) (*FunctionDeclaration, error) {
+	if err := checkFunctionDeclaration(result, params); err != nil {
+		return nil, err
+	}
+	return &FunctionDeclaration{
+		Name:   name,
+		Params: params,
+		Result: result,
+	}, nil
+}
+
+func checkFunctionDeclaration(result ExpressionType, params []ExpressionType) error {
+	for _, tpe := range append([]ExpressionType{result}, params...) {
+		if !tpe.IsInteger() && !tpe.IsFloat() && tpe != BoolType {
+			return ErrIncorrectType
+		}
+	}
+	return nil
+}
+
+// String возвращает сигнатуру функции, например "hash(int, int) uint32"
+func (fd *FunctionDeclaration) String() string {
+	params := make([]string, len(fd.Params))
+	for i, param := range fd.Params {
+		params[i] = param.String()
+	}
+	return fmt.Sprintf("%s(%s) %s", fd.Name, strings.Join(params, ", "), fd.Result)
+}
+
+// FunctionApplication представляет применение неинтерпретируемой функции к аргументам.
+// Используется для абстракции вызовов, которые нельзя или не нужно исполнять:
+// хеш-функций, внешних библиотек, глубокой рекурсии.
+type FunctionApplication struct {
+	Function *FunctionDeclaration
+	Args     []SymbolicExpression
+}
+
+// NewFunctionApplication создаёт применение функции.
+// Число и типы аргументов должны соответствовать объявлению.
+func NewFunctionApplication(function *FunctionDeclaration, args ...SymbolicExpression) *FunctionApplication {
+	res, err := NewFunctionApplicationChecked(function, args...)
+	if err != nil {
+		panic(err.Error())
+	}
+	return res
+}
+
+// NewFunctionApplicationChecked создаёт применение функции,
+// возвращая ошибку вместо паники, если аргументы не соответствуют объявлению
+func NewFunctionApplicationChecked(function *FunctionDeclaration, args ...SymbolicExpression) (*FunctionApplication, error) {
+	if err := checkFunctionApplication(function, args); err != nil {
+		return nil, err
+	}
+	return &FunctionApplication{
+		Function: function,
+		Args:     args,
+	}, nil
+}
+
+func checkFunctionApplication(function *FunctionDeclaration, args []SymbolicExpression) error {
+	if function == nil {
+		return ErrMissingOperand
+	}
+	if err := checkFunctionDeclaration(function.Result, function.Params); err != nil {
+		return err
+	}
+	if len(args) != len(function.Params) {
+		return ErrArgumentCount
+	}
+	for i, arg := range args {
+		if arg == nil {
+			return ErrMissingOperand
+		}
+		if arg.Type() != function.Params[i] {
+			return ErrIncompatibleTypes
+		}
+	}
+	return nil
+}
+
+// Type возвращает тип результата функции
+func (fa *FunctionApplication) Type() ExpressionType {
+	return fa.Function.Result
+}
+
+// String возвращает строковое представление применения, например "hash(x, 1)"
+func (fa *FunctionApplication) String() string {
+	args := make([]string, len(fa.Args))
+	for i, arg := range fa.Args {
+		var param *ExpressionType
+		if i < len(fa.Function.Params) {
+			param = &fa.Function.Params[i]
+		}
+		args[i] = operandString(arg, param)
+	}
+	return fmt.Sprintf("%s(%s)", fa.Function.Name, strings.Join(args, ", "))
+}
+
+// Accept реализует Visitor pattern
+func (fa *FunctionApplication) Accept(visitor Visitor) interface{} {
+	return visitor.VisitFunctionApplication(fa)
+}
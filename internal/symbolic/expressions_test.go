@@ -0,0 +1,66 @@
+package symbolic
+
+import "testing"
+
+func TestIteSimplification(t *testing.T) {
+	x := NewSymbolicVariable("x", IntType)
+	y := NewSymbolicVariable("y", IntType)
+	c := NewBinaryOperation(x, y, LT)
+	not := NewLogicalOperation([]SymbolicExpression{c}, NOT)
+
+	tests := []struct {
+		name     string
+		expr     SymbolicExpression
+		expected string
+	}{
+		{"true condition", Ite(NewBoolConstant(true), x, y), "x"},
+		{"false condition", Ite(NewBoolConstant(false), x, y), "y"},
+		{"same branches", Ite(c, x, x), "x"},
+		{"boolean identity", Ite(c, NewBoolConstant(true), NewBoolConstant(false)), "(x < y)"},
+		{"boolean negation", Ite(c, NewBoolConstant(false), NewBoolConstant(true)), "!(x < y)"},
+		{"negated condition", Ite(not, x, y), "((x < y) ? y : x)"},
+		{"no simplification", Ite(c, x, y), "((x < y) ? x : y)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.expr.String(); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	if Ite(c, x, y).Type() != IntType {
+		t.Error("conditional expression must have the type of its branches")
+	}
+}
+
+func TestStringOperations(t *testing.T) {
+	s := NewSymbolicVariable("s", StringType)
+	b := NewSymbolicVariable("b", BytesType)
+
+	tests := []struct {
+		name         string
+		expr         SymbolicExpression
+		expected     string
+		expectedType ExpressionType
+	}{
+		{"concat", NewBinaryOperation(s, NewStringConstant("\n"), ADD), `(s + "\n")`, StringType},
+		{"len", NewStringOperation(LEN, b), "len(b)", IntType},
+		{"at", NewStringOperation(AT, s, NewIntConstant(0)), "at(s, 0)", ByteType},
+		{"slice", NewStringOperation(SLICE, b, NewIntConstant(1), NewStringOperation(LEN, b)), "slice(b, 1, len(b))", BytesType},
+		{"contains", NewStringOperation(CONTAINS, s, NewStringConstant("=")), `contains(s, "=")`, BoolType},
+		{"conversion", NewStringOperation(FROM_BYTES, NewStringOperation(TO_BYTES, s)), "string(bytes(s))", StringType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.expr.String(); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+			if got := tt.expr.Type(); got != tt.expectedType {
+				t.Errorf("expected type %s, got %s", tt.expectedType, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package symbolic
+
+import "sync"
+
+// Factory интернирует выражения (hash-consing): для каждого структурно различного
+// выражения выдаётся единственный общий экземпляр. Интернированные выражения равны
+// тогда и только тогда, когда равны их указатели, поэтому их можно сравнивать через ==
+// и использовать как ключи map, а одинаковые подвыражения разных состояний не дублируются.
+//
+// Ссылки на объекты (Ref) не интернируются: их значение зависит от памяти состояния.
+// Factory безопасна для одновременного использования.
+type Factory struct {
+	mu     sync.Mutex
+	table  map[uint64][]SymbolicExpression
+	hashes map[SymbolicExpression]uint64
+}
+
+// NewFactory создаёт пустую фабрику выражений
+func NewFactory() *Factory {
+	return &Factory{
+		table:  map[uint64][]SymbolicExpression{},
+		hashes: map[SymbolicExpression]uint64{},
+	}
+}
+
+// Intern возвращает общий экземпляр выражения, структурно равного expr.
+// Подвыражения интернируются рекурсивно, сам expr не изменяется.
+func (f *Factory) Intern(expr SymbolicExpression) SymbolicExpression {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.intern(expr)
+}
+
+// Size возвращает число различных выражений, созданных фабрикой
+func (f *Factory) Size() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.hashes)
+}
+
+func (f *Factory) intern(expr SymbolicExpression) SymbolicExpression {
+	if _, ok := expr.(*Ref); ok {
+		return expr
+	}
+	if _, ok := f.hashes[expr]; ok {
+		return expr
+	}
+
+	node := f.withInternedChildren(expr)
+	// Подвыражения уже интернированы, поэтому их хеши берутся из таблицы,
+	// а сравнение подвыражений сводится к сравнению указателей
+	hash := hashNode(node, f.hash)
+	for _, candidate := range f.table[hash] {
+		if equalNode(candidate, node, func(x, y SymbolicExpression) bool { return x == y }) {
+			return candidate
+		}
+	}
+
+	f.table[hash] = append(f.table[hash], node)
+	f.hashes[node] = hash
+	return node
+}
+
+func (f *Factory) hash(expr SymbolicExpression) uint64 {
+	if hash, ok := f.hashes[expr]; ok {
+		return hash
+	}
+	return expr.Hash()
+}
+
+// withInternedChildren возвращает узел с интернированными подвыражениями,
+// создавая копию узла, только если хотя бы одно подвыражение заменилось
+func (f *Factory) withInternedChildren(expr SymbolicExpression) SymbolicExpression {
+	children := Children(expr)
+	if len(children) == 0 {
+		return expr
+	}
+
+	interned := make([]SymbolicExpression, len(children))
+	for i, child := range children {
+		interned[i] = f.intern(child)
+	}
+	return WithChildren(expr, interned)
+}
@@ -0,0 +1,82 @@
+package symbolic
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEqualAndHash(t *testing.T) {
+	x := func() SymbolicExpression { return NewSymbolicVariable("x", IntType) }
+	sum := func(c int64) SymbolicExpression { return NewBinaryOperation(x(), NewIntConstant(c), ADD) }
+	f := NewFunctionDeclaration("h", IntType, IntType)
+
+	tests := []struct {
+		name  string
+		a, b  SymbolicExpression
+		equal bool
+	}{
+		{"same structure", sum(1), sum(1), true},
+		{"different constant", sum(1), sum(2), false},
+		{"different type", NewIntConstant(1), NewTypedIntConstant(1, Int64Type), false},
+		{"different variable type", x(), NewSymbolicVariable("x", Int32Type), false},
+		{"different operator", NewBinaryOperation(x(), x(), ADD), NewBinaryOperation(x(), x(), MUL), false},
+		{"NaN", NewFloatConstant(math.NaN()), NewFloatConstant(math.NaN()), true},
+		{"signed zero", NewFloatConstant(0), NewFloatConstant(math.Copysign(0, -1)), false},
+		{"string", NewStringConstant("a"), NewStringConstant("a"), true},
+		{"application", NewFunctionApplication(f, sum(1)), NewFunctionApplication(f, sum(1)), true},
+		{"operand count", NewLogicalOperation([]SymbolicExpression{NewBoolConstant(true), NewBoolConstant(true)}, AND),
+			NewLogicalOperation([]SymbolicExpression{NewBoolConstant(true), NewBoolConstant(true), NewBoolConstant(true)}, AND), false},
+		{"different kinds", NewConversion(x(), Int32Type), NewSymbolicVariable("x", Int32Type), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Equal(tt.b); got != tt.equal {
+				t.Errorf("%s.Equal(%s) = %t, expected %t", tt.a, tt.b, got, tt.equal)
+			}
+			if tt.b.Equal(tt.a) != tt.a.Equal(tt.b) {
+				t.Errorf("Equal is not symmetric for %s and %s", tt.a, tt.b)
+			}
+			if tt.equal && tt.a.Hash() != tt.b.Hash() {
+				t.Errorf("equal expressions %s have different hashes", tt.a)
+			}
+		})
+	}
+}
+
+func TestFactoryIntern(t *testing.T) {
+	factory := NewFactory()
+	x := NewSymbolicVariable("x", IntType)
+	cond := func() SymbolicExpression {
+		return NewLogicalOperation([]SymbolicExpression{
+			NewBinaryOperation(x, NewIntConstant(0), GT),
+			NewBinaryOperation(NewSymbolicVariable("x", IntType), NewIntConstant(10), LT),
+		}, AND)
+	}
+
+	a := factory.Intern(cond())
+	b := factory.Intern(cond())
+	if a != b {
+		t.Fatalf("structurally equal expressions are interned to different instances")
+	}
+	if !a.Equal(cond()) || a.Hash() != cond().Hash() {
+		t.Errorf("interned expression differs from the original")
+	}
+
+	// x, 0, 10, два сравнения и конъюнкция
+	if size := factory.Size(); size != 6 {
+		t.Errorf("expected 6 distinct expressions, got %d", size)
+	}
+
+	// Общие подвыражения разных выражений совпадают по указателю
+	c := factory.Intern(NewBinaryOperation(NewSymbolicVariable("x", IntType), NewIntConstant(0), GT))
+	if c != a.(*LogicalOperation).Operands[0] {
+		t.Errorf("shared subexpression is not reused")
+	}
+
+	// Интернированные выражения можно использовать как ключи map
+	cache := map[SymbolicExpression]int{a: 1}
+	if cache[factory.Intern(cond())] != 1 {
+		t.Errorf("interned expression is not found in map")
+	}
+}
@@ -0,0 +1,543 @@
+package symbolic
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// JSONVersion - версия формата JSON, которую пишет EncodeJSON.
+// Версия увеличивается при любом несовместимом изменении формата,
+// DecodeJSON отказывается читать документы неизвестных версий.
+const JSONVersion = 1
+
+// Формат документа: {"version": 1, "expr": узел}. Узел - объект с полем "kind"
+// и полями своего вида:
+//
+//	variable    name, type, sort (только для массивов: {"index": тип, "element": тип})
+//	int         type, value (десятичная строка; беззнаковые типы - без знака)
+//	float       type, value (строка strconv.FormatFloat: "0.1", "-0", "NaN", "+Inf")
+//	bool        value (true или false)
+//	string      value (строка UTF-8) или base64 (байты, не являющиеся корректным UTF-8)
+//	binary      op, left, right
+//	unary       op, operand
+//	logical     op, operands
+//	ite         cond, then, else
+//	select      array, index
+//	store       array, index, elem
+//	apply       function ({"name", "params", "result"}), args
+//	string_op   op, operands
+//	conversion  type, operand
+//	ref         type, ptr
+//
+// Типы и операторы записываются так же, как в строковом представлении выражений
+// ("int8", "[]byte", "+", "&&", "hasPrefix"). Все NaN записываются как "NaN"
+// и читаются как math.NaN(). Ссылка сохраняет только тип и адрес объекта:
+// память, в которой он размещён, не сериализуется.
+
+// jsonDocument - корень JSON документа
+type jsonDocument struct {
+	Version int       `json:"version"`
+	Expr    *jsonNode `json:"expr"`
+}
+
+// jsonNode - узел выражения любого вида; неиспользуемые видом поля опускаются
+type jsonNode struct {
+	Kind     string          `json:"kind"`
+	Name     string          `json:"name,omitempty"`
+	Type     string          `json:"type,omitempty"`
+	Sort     *jsonSort       `json:"sort,omitempty"`
+	Op       string          `json:"op,omitempty"`
+	Value    json.RawMessage `json:"value,omitempty"`
+	Base64   string          `json:"base64,omitempty"`
+	Ptr      int64           `json:"ptr,omitempty"`
+	Function *jsonFunction   `json:"function,omitempty"`
+
+	Left     *jsonNode   `json:"left,omitempty"`
+	Right    *jsonNode   `json:"right,omitempty"`
+	Operand  *jsonNode   `json:"operand,omitempty"`
+	Operands []*jsonNode `json:"operands,omitempty"`
+	Cond     *jsonNode   `json:"cond,omitempty"`
+	Then     *jsonNode   `json:"then,omitempty"`
+	Else     *jsonNode   `json:"else,omitempty"`
+	Array    *jsonNode   `json:"array,omitempty"`
+	Index    *jsonNode   `json:"index,omitempty"`
+	Elem     *jsonNode   `json:"elem,omitempty"`
+	Args     []*jsonNode `json:"args,omitempty"`
+}
+
+type jsonSort struct {
+	Index   string `json:"index"`
+	Element string `json:"element"`
+}
+
+type jsonFunction struct {
+	Name   string   `json:"name"`
+	Params []string `json:"params"`
+	Result string   `json:"result"`
+}
+
+// EncodeJSON записывает выражение в JSON формате версии JSONVersion.
+// Некорректные выражения (см. Validate) не записываются.
+func EncodeJSON(expr SymbolicExpression) ([]byte, error) {
+	if err := Validate(expr); err != nil {
+		return nil, err
+	}
+	return marshal(jsonDocument{
+		Version: JSONVersion,
+		Expr:    encodeNode(expr),
+	})
+}
+
+// DecodeJSON читает выражение, записанное EncodeJSON. Узлы создаются конструкторами
+// New...Checked, поэтому некорректный документ приводит к ошибке, а не к панике.
+// Ошибка содержит путь к узлу в терминах полей выражения, например "Operands[1].Left".
+func DecodeJSON(data []byte) (SymbolicExpression, error) {
+	var doc jsonDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Version != JSONVersion {
+		return nil, fmt.Errorf("unsupported expression format version %d", doc.Version)
+	}
+
+	return decodeNode("", doc.Expr)
+}
+
+// encodeNode строит JSON узел для корректного выражения
+func encodeNode(expr SymbolicExpression) *jsonNode {
+	if ref, ok := expr.(*Ref); ok {
+		return &jsonNode{Kind: "ref", Type: ref.Tpe.String(), Ptr: ref.Ptr}
+	}
+	return expr.Accept(jsonEncoder{}).(*jsonNode)
+}
+
+func encodeNodes(exprs []SymbolicExpression) []*jsonNode {
+	res := make([]*jsonNode, len(exprs))
+	for i, expr := range exprs {
+		res[i] = encodeNode(expr)
+	}
+	return res
+}
+
+// marshal записывает значение в JSON, не экранируя <, > и &,
+// которые часто встречаются в операторах
+func marshal(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// rawJSON записывает значение, которое заведомо сериализуется без ошибок
+func rawJSON(value interface{}) json.RawMessage {
+	res, err := marshal(value)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// jsonEncoder реализует Visitor, переводя узлы выражения в JSON узлы
+type jsonEncoder struct{}
+
+func (jsonEncoder) VisitVariable(expr *SymbolicVariable) interface{} {
+	node := &jsonNode{Kind: "variable", Name: expr.Name, Type: expr.ExprType.String()}
+	if expr.ExprType == ArrayType {
+		node.Sort = &jsonSort{Index: expr.Sort.Index.String(), Element: expr.Sort.Element.String()}
+	}
+	return node
+}
+
+func (jsonEncoder) VisitIntConstant(expr *IntConstant) interface{} {
+	value, _, _ := constantText(expr)
+	return &jsonNode{Kind: "int", Type: expr.Type().String(), Value: rawJSON(value)}
+}
+
+func (jsonEncoder) VisitBoolConstant(expr *BoolConstant) interface{} {
+	return &jsonNode{Kind: "bool", Value: rawJSON(expr.Value)}
+}
+
+func (jsonEncoder) VisitFloatConstant(expr *FloatConstant) interface{} {
+	value := strconv.FormatFloat(expr.Value, 'g', -1, 64)
+	return &jsonNode{Kind: "float", Type: expr.Type().String(), Value: rawJSON(value)}
+}
+
+func (jsonEncoder) VisitStringConstant(expr *StringConstant) interface{} {
+	if !utf8.ValidString(expr.Value) {
+		return &jsonNode{Kind: "string", Base64: base64.StdEncoding.EncodeToString([]byte(expr.Value))}
+	}
+	return &jsonNode{Kind: "string", Value: rawJSON(expr.Value)}
+}
+
+func (jsonEncoder) VisitBinaryOperation(expr *BinaryOperation) interface{} {
+	return &jsonNode{Kind: "binary", Op: expr.Operator.String(), Left: encodeNode(expr.Left), Right: encodeNode(expr.Right)}
+}
+
+func (jsonEncoder) VisitUnaryOperation(expr *UnaryOperation) interface{} {
+	return &jsonNode{Kind: "unary", Op: expr.Operator.String(), Operand: encodeNode(expr.Left)}
+}
+
+func (jsonEncoder) VisitLogicalOperation(expr *LogicalOperation) interface{} {
+	return &jsonNode{Kind: "logical", Op: expr.Operator.String(), Operands: encodeNodes(expr.Operands)}
+}
+
+func (jsonEncoder) VisitConditionalExpression(expr *ConditionalExpression) interface{} {
+	return &jsonNode{
+		Kind: "ite",
+		Cond: encodeNode(expr.Condition),
+		Then: encodeNode(expr.Then),
+		Else: encodeNode(expr.Else),
+	}
+}
+
+func (jsonEncoder) VisitArraySelect(expr *ArraySelect) interface{} {
+	return &jsonNode{Kind: "select", Array: encodeNode(expr.Array), Index: encodeNode(expr.Index)}
+}
+
+func (jsonEncoder) VisitArrayStore(expr *ArrayStore) interface{} {
+	return &jsonNode{
+		Kind:  "store",
+		Array: encodeNode(expr.Array),
+		Index: encodeNode(expr.Index),
+		Elem:  encodeNode(expr.Value),
+	}
+}
+
+func (jsonEncoder) VisitFunctionApplication(expr *FunctionApplication) interface{} {
+	function := &jsonFunction{Name: expr.Function.Name, Params: []string{}, Result: expr.Function.Result.String()}
+	for _, param := range expr.Function.Params {
+		function.Params = append(function.Params, param.String())
+	}
+	return &jsonNode{Kind: "apply", Function: function, Args: encodeNodes(expr.Args)}
+}
+
+func (jsonEncoder) VisitStringOperation(expr *StringOperation) interface{} {
+	return &jsonNode{Kind: "string_op", Op: expr.Operator.String(), Operands: encodeNodes(expr.Operands)}
+}
+
+func (jsonEncoder) VisitConversion(expr *Conversion) interface{} {
+	return &jsonNode{Kind: "conversion", Type: expr.Target.String(), Operand: encodeNode(expr.Operand)}
+}
+
+// decodeNode восстанавливает выражение из JSON узла. Ошибка оборачивается в DecodeError
+// с путём к узлу, в котором она возникла; ошибки подвыражений передаются без изменений.
+func decodeNode(path string, node *jsonNode) (SymbolicExpression, error) {
+	res, err := decodeFields(path, node)
+	if err != nil {
+		if _, ok := err.(*DecodeError); !ok {
+			err = &DecodeError{Path: path, Err: err}
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+func decodeFields(path string, node *jsonNode) (SymbolicExpression, error) {
+	if node == nil {
+		return nil, ErrMissingOperand
+	}
+
+	child := func(field string, node *jsonNode) (SymbolicExpression, error) {
+		return decodeNode(joinPath(path, field), node)
+	}
+	children := func(field string, nodes []*jsonNode) ([]SymbolicExpression, error) {
+		res := make([]SymbolicExpression, len(nodes))
+		for i, node := range nodes {
+			expr, err := child(fmt.Sprintf("%s[%d]", field, i), node)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = expr
+		}
+		return res, nil
+	}
+
+	switch node.Kind {
+	case "variable":
+		tpe, err := parseType(node.Type)
+		if err != nil {
+			return nil, err
+		}
+		if tpe != ArrayType {
+			return NewSymbolicVariable(node.Name, tpe), nil
+		}
+		if node.Sort == nil {
+			return nil, fmt.Errorf("array variable %s has no sort", node.Name)
+		}
+		index, err := parseType(node.Sort.Index)
+		if err != nil {
+			return nil, err
+		}
+		element, err := parseType(node.Sort.Element)
+		if err != nil {
+			return nil, err
+		}
+		if !isScalarType(index) || !isScalarType(element) {
+			return nil, ErrIncorrectType
+		}
+		return NewArrayVariable(node.Name, ArraySort{Index: index, Element: element}), nil
+	case "int":
+		return decodeInt(node)
+	case "float":
+		return decodeFloat(node)
+	case "bool":
+		var value bool
+		if err := json.Unmarshal(node.Value, &value); err != nil {
+			return nil, fmt.Errorf("invalid bool value: %w", err)
+		}
+		return NewBoolConstant(value), nil
+	case "string":
+		if node.Base64 != "" {
+			value, err := base64.StdEncoding.DecodeString(node.Base64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string value: %w", err)
+			}
+			return NewStringConstant(string(value)), nil
+		}
+		var value string
+		if err := json.Unmarshal(node.Value, &value); err != nil {
+			return nil, fmt.Errorf("invalid string value: %w", err)
+		}
+		return NewStringConstant(value), nil
+	case "binary":
+		op, ok := binaryOperators[node.Op]
+		if !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownOperator, node.Op)
+		}
+		left, err := child("Left", node.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := child("Right", node.Right)
+		if err != nil {
+			return nil, err
+		}
+		return NewBinaryOperationChecked(left, right, op)
+	case "unary":
+		op, ok := unaryOperators[node.Op]
+		if !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownOperator, node.Op)
+		}
+		operand, err := child("Left", node.Operand)
+		if err != nil {
+			return nil, err
+		}
+		return NewUnaryOperationChecked(operand, op)
+	case "logical":
+		op, ok := logicalOperators[node.Op]
+		if !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownOperator, node.Op)
+		}
+		operands, err := children("Operands", node.Operands)
+		if err != nil {
+			return nil, err
+		}
+		return NewLogicalOperationChecked(operands, op)
+	case "ite":
+		cond, err := child("Condition", node.Cond)
+		if err != nil {
+			return nil, err
+		}
+		then, err := child("Then", node.Then)
+		if err != nil {
+			return nil, err
+		}
+		els, err := child("Else", node.Else)
+		if err != nil {
+			return nil, err
+		}
+		return NewConditionalExpressionChecked(cond, then, els)
+	case "select":
+		array, err := child("Array", node.Array)
+		if err != nil {
+			return nil, err
+		}
+		index, err := child("Index", node.Index)
+		if err != nil {
+			return nil, err
+		}
+		return NewArraySelectChecked(array, index)
+	case "store":
+		array, err := child("Array", node.Array)
+		if err != nil {
+			return nil, err
+		}
+		index, err := child("Index", node.Index)
+		if err != nil {
+			return nil, err
+		}
+		value, err := child("Value", node.Elem)
+		if err != nil {
+			return nil, err
+		}
+		return NewArrayStoreChecked(array, index, value)
+	case "apply":
+		function, err := decodeFunction(node.Function)
+		if err != nil {
+			return nil, err
+		}
+		args, err := children("Args", node.Args)
+		if err != nil {
+			return nil, err
+		}
+		return NewFunctionApplicationChecked(function, args...)
+	case "string_op":
+		op, ok := stringOperators[node.Op]
+		if !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownOperator, node.Op)
+		}
+		operands, err := children("Operands", node.Operands)
+		if err != nil {
+			return nil, err
+		}
+		return NewStringOperationChecked(op, operands...)
+	case "conversion":
+		target, err := parseType(node.Type)
+		if err != nil {
+			return nil, err
+		}
+		operand, err := child("Operand", node.Operand)
+		if err != nil {
+			return nil, err
+		}
+		return NewConversionChecked(operand, target)
+	case "ref":
+		tpe, err := parseType(node.Type)
+		if err != nil {
+			return nil, err
+		}
+		return &Ref{Tpe: tpe, Ptr: node.Ptr}, nil
+	}
+	return nil, fmt.Errorf("unknown expression kind %q", node.Kind)
+}
+
+func decodeInt(node *jsonNode) (SymbolicExpression, error) {
+	tpe, err := parseType(node.Type)
+	if err != nil {
+		return nil, err
+	}
+	if !tpe.IsInteger() {
+		return nil, ErrIncorrectType
+	}
+
+	var text string
+	if err := json.Unmarshal(node.Value, &text); err != nil {
+		return nil, fmt.Errorf("invalid integer value: %w", err)
+	}
+
+	var value int64
+	if tpe.IsSigned() {
+		value, err = strconv.ParseInt(text, 10, tpe.Bits())
+	} else {
+		var u uint64
+		u, err = strconv.ParseUint(text, 10, tpe.Bits())
+		value = int64(u)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrValueOutOfRange, tpe, text)
+	}
+	return NewTypedIntConstantChecked(value, tpe)
+}
+
+func decodeFloat(node *jsonNode) (SymbolicExpression, error) {
+	tpe, err := parseType(node.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	var text string
+	if err := json.Unmarshal(node.Value, &text); err != nil {
+		return nil, fmt.Errorf("invalid float value: %w", err)
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid float value %q", text)
+	}
+	if math.IsNaN(value) {
+		value = math.NaN()
+	}
+	if tpe == Float32Type && !math.IsNaN(value) && float64(float32(value)) != value {
+		return nil, fmt.Errorf("%w: %s %s", ErrValueOutOfRange, tpe, text)
+	}
+	return NewTypedFloatConstantChecked(value, tpe)
+}
+
+func decodeFunction(function *jsonFunction) (*FunctionDeclaration, error) {
+	if function == nil {
+		return nil, ErrMissingOperand
+	}
+	result, err := parseType(function.Result)
+	if err != nil {
+		return nil, err
+	}
+	params := make([]ExpressionType, len(function.Params))
+	for i, param := range function.Params {
+		if params[i], err = parseType(param); err != nil {
+			return nil, err
+		}
+	}
+	return NewFunctionDeclarationChecked(function.Name, result, params...)
+}
+
+// DecodeError сообщает, в каком узле документа произошла ошибка чтения
+type DecodeError struct {
+	// Path - путь к узлу от корня по именам полей выражения, например "Operands[1].Left";
+	// пуст для корня
+	Path string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// parseType возвращает тип по его строковому представлению
+func parseType(name string) (ExpressionType, error) {
+	for tpe := IntType; tpe <= BytesType; tpe++ {
+		if tpe.String() == name {
+			return tpe, nil
+		}
+	}
+	return 0, fmt.Errorf("%w %q", ErrIncorrectType, name)
+}
+
+// Операторы по их строковому представлению
+var (
+	binaryOperators  = operatorNames(ADD, SHR)
+	logicalOperators = operatorNames(AND, IMPLIES)
+	unaryOperators   = operatorNames(BNOT, BNOT)
+	stringOperators  = operatorNames(LEN, FROM_BYTES)
+)
+
+func operatorNames[Op interface {
+	~int
+	String() string
+}](first, last Op) map[string]Op {
+	res := map[string]Op{}
+	for op := first; op <= last; op++ {
+		res[op.String()] = op
+	}
+	return res
+}
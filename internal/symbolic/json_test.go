@@ -0,0 +1,111 @@
+package symbolic
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	x := NewSymbolicVariable("x", IntType)
+	b := NewSymbolicVariable("b", BoolType)
+	s := NewSymbolicVariable("s", StringType)
+	a := NewArrayVariable("a", ArraySort{Index: Uint8Type, Element: Float32Type})
+	h := NewFunctionDeclaration("h", Uint32Type, IntType, BoolType)
+
+	tests := []struct {
+		name string
+		expr SymbolicExpression
+	}{
+		{"variable", x},
+		{"max uint64", NewTypedIntConstant(-1, Uint64Type)},
+		{"negative int8", NewTypedIntConstant(-128, Int8Type)},
+		{"NaN", NewFloatConstant(math.NaN())},
+		{"negative zero", NewFloatConstant(math.Copysign(0, -1))},
+		{"infinity", NewTypedFloatConstant(math.Inf(-1), Float32Type)},
+		{"float32", NewTypedFloatConstant(0.1, Float32Type)},
+		{"utf-8 string", NewStringConstant("привет \"мир\"")},
+		{"bytes string", NewStringConstant("\xff\x00a")},
+		{"binary", NewBinaryOperation(NewBinaryOperation(x, NewIntConstant(1), ADD), x, LE)},
+		{"unary", NewUnaryOperation(NewTypedIntConstant(3, Uint16Type), BNOT)},
+		{"logical", NewLogicalOperation([]SymbolicExpression{b, NewLogicalOperation([]SymbolicExpression{b}, NOT), NewBoolConstant(true)}, OR)},
+		{"ite", NewConditionalExpression(b, s, NewStringConstant(""))},
+		{"array", NewArraySelect(NewArrayStore(a, NewTypedIntConstant(1, Uint8Type), NewTypedFloatConstant(2, Float32Type)), NewTypedIntConstant(1, Uint8Type))},
+		{"application", NewFunctionApplication(h, x, b)},
+		{"string operation", NewStringOperation(SLICE, NewStringOperation(TO_BYTES, s), NewIntConstant(0), NewStringOperation(LEN, s))},
+		{"conversion", NewConversion(NewConversion(x, Float32Type), Int16Type)},
+		{"ref", &Ref{Tpe: ObjectType, Ptr: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := EncodeJSON(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := DecodeJSON(data)
+			if err != nil {
+				t.Fatalf("decoding %s: %v", data, err)
+			}
+			if !decoded.Equal(tt.expr) {
+				t.Errorf("expected %s, got %s (%s)", tt.expr, decoded, data)
+			}
+		})
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	expr := NewBinaryOperation(NewSymbolicVariable("x", Uint8Type), NewTypedIntConstant(255, Uint8Type), LT)
+	data, err := EncodeJSON(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"version":1,"expr":{"kind":"binary","op":"<",` +
+		`"left":{"kind":"variable","name":"x","type":"uint8"},` +
+		`"right":{"kind":"int","type":"uint8","value":"255"}}}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+}
+
+func TestJSONDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		path     string
+		expected error
+	}{
+		{"unknown version", `{"version":2,"expr":{"kind":"bool","value":true}}`, "", nil},
+		{"unknown kind", `{"version":1,"expr":{"kind":"lambda"}}`, "", nil},
+		{"missing operand", `{"version":1,"expr":{"kind":"binary","op":"+","left":{"kind":"int","type":"int","value":"1"}}}`,
+			"Right", ErrMissingOperand},
+		{"incompatible types", `{"version":1,"expr":{"kind":"logical","op":"&&","operands":[
+			{"kind":"bool","value":true},
+			{"kind":"binary","op":"==","left":{"kind":"variable","name":"x","type":"int"},"right":{"kind":"bool","value":true}}
+		]}}`, "Operands[1]", ErrIncompatibleTypes},
+		{"unknown operator", `{"version":1,"expr":{"kind":"unary","op":"!","operand":{"kind":"variable","name":"x","type":"int"}}}`,
+			"", ErrUnknownOperator},
+		{"unknown type", `{"version":1,"expr":{"kind":"ite","cond":{"kind":"variable","name":"c","type":"bool"},
+			"then":{"kind":"variable","name":"x","type":"complex128"},"else":{"kind":"int","type":"int","value":"0"}}}`,
+			"Then", ErrIncorrectType},
+		{"out of range", `{"version":1,"expr":{"kind":"int","type":"int8","value":"200"}}`, "", ErrValueOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeJSON([]byte(tt.data))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if tt.expected != nil && !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+
+			var decodeErr *DecodeError
+			if errors.As(err, &decodeErr) && decodeErr.Path != tt.path {
+				t.Errorf("expected path %q, got %q (%v)", tt.path, decodeErr.Path, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,782 @@
+package symbolic
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Parser разбирает выражения в синтаксисе, который печатает String(), например
+// "((x + 1) > y)", "!(s == \"ab\")", "(c ? a[i := 1][j] : hash(x, 2))", "int32(len(s))".
+// Скобки вокруг бинарных операций необязательны: операторы имеют приоритеты Go,
+// "=>" связывает слабее "||", а "c ? a : b" - слабее всех операторов.
+//
+// Перед выражением можно объявить переменные и функции, разделяя объявления ";" или пробелами:
+//
+//	var x, y int; var s string; var a [int]uint8; func hash(int, int) uint32
+//	x > 0 && y < 10
+//
+// Объявления сохраняются в Parser и доступны при разборе следующих выражений.
+// Целые и дробные литералы, как нетипизированные константы Go, получают тип из контекста:
+// в "(x + 1)" для x типа uint8 константа 1 имеет тип uint8. Без контекста литерал
+// имеет тип int или float64 (FloatType), а T(литерал) - константа типа T.
+// String печатает константу, тип которой не следует из контекста, в виде T(v),
+// а числа с плавающей точкой - без потери точности, поэтому Parse(e.String())
+// восстанавливает e. Ссылки (Ref) не разбираются.
+type Parser struct {
+	// Variables - объявленные переменные по имени
+	Variables map[string]*SymbolicVariable
+	// Functions - объявленные неинтерпретируемые функции по имени
+	Functions map[string]*FunctionDeclaration
+}
+
+// NewParser создаёт парсер с объявленными переменными
+func NewParser(vars ...*SymbolicVariable) *Parser {
+	p := &Parser{
+		Variables: map[string]*SymbolicVariable{},
+		Functions: map[string]*FunctionDeclaration{},
+	}
+	for _, v := range vars {
+		p.Variables[v.Name] = v
+	}
+	return p
+}
+
+// Parse разбирает объявления и выражение новым парсером
+func Parse(input string) (SymbolicExpression, error) {
+	return NewParser().Parse(input)
+}
+
+// ParseError описывает ошибку разбора с позицией во входной строке
+type ParseError struct {
+	// Line и Column - строка и столбец (в байтах) начала ошибочного фрагмента, с 1
+	Line, Column int
+	// Err - причина; ошибки типизации оборачивают ErrIncompatibleTypes и другие ошибки Err...
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%d:%d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Parse разбирает объявления и выражение
+func (p *Parser) Parse(input string) (res SymbolicExpression, err error) {
+	ps := &parseState{parser: p, input: input}
+	defer func() {
+		if r := recover(); r != nil {
+			perr, ok := r.(*ParseError)
+			if !ok {
+				panic(r)
+			}
+			res, err = nil, perr
+		}
+	}()
+
+	ps.next()
+	ps.declarations()
+	if ps.tok.kind == tokEOF {
+		ps.failf(ps.tok.pos, "expected expression")
+	}
+	node := ps.expression()
+	if ps.tok.kind != tokEOF {
+		ps.failf(ps.tok.pos, "unexpected %s", ps.tok)
+	}
+	return ps.build(node, nil), nil
+}
+
+// Лексический анализ
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokInt
+	tokFloat
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of input"
+	}
+	return strconv.Quote(t.text)
+}
+
+// operators - операторы и разделители; более длинные проверяются первыми
+var operators = []string{
+	"<<", ">>", "<=", ">=", "==", "!=", "&&", "||", "=>", ":=",
+	"+", "-", "*", "/", "%", "&", "|", "^", "<", ">", "!",
+	"(", ")", "[", "]", ",", "?", ":", ";",
+}
+
+// isIdentRune сообщает, может ли символ продолжать идентификатор.
+// Кроме букв и цифр допускаются символы из имён, которые дают Rename и SSA: "f#1.x".
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '#' || r == '$'
+}
+
+// scan читает следующий токен, начиная с позиции offset
+func (ps *parseState) scan() token {
+	input := ps.input
+	for ps.offset < len(input) {
+		r, size := utf8.DecodeRuneInString(input[ps.offset:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		ps.offset += size
+	}
+	start := ps.offset
+	if start == len(input) {
+		return token{kind: tokEOF, pos: start}
+	}
+
+	r, _ := utf8.DecodeRuneInString(input[start:])
+	switch {
+	case unicode.IsLetter(r) || r == '_':
+		end := start
+		for end < len(input) {
+			r, size := utf8.DecodeRuneInString(input[end:])
+			if !isIdentRune(r) {
+				break
+			}
+			end += size
+		}
+		ps.offset = end
+		return token{kind: tokIdent, text: input[start:end], pos: start}
+	case r >= '0' && r <= '9':
+		return ps.scanNumber(start)
+	case r == '"' || r == '`':
+		prefix, err := strconv.QuotedPrefix(input[start:])
+		if err != nil {
+			ps.failf(start, "unterminated string literal")
+		}
+		ps.offset = start + len(prefix)
+		return token{kind: tokString, text: prefix, pos: start}
+	}
+
+	for _, op := range operators {
+		if strings.HasPrefix(input[start:], op) {
+			ps.offset = start + len(op)
+			return token{kind: tokOp, text: op, pos: start}
+		}
+	}
+	ps.failf(start, "unexpected character %q", r)
+	return token{}
+}
+
+// scanNumber читает десятичный или шестнадцатеричный целый литерал либо десятичный дробный
+func (ps *parseState) scanNumber(start int) token {
+	input := ps.input
+	end := start
+	kind := tokInt
+	hex := strings.HasPrefix(input[start:], "0x") || strings.HasPrefix(input[start:], "0X")
+	if hex {
+		end += 2
+	}
+	for end < len(input) {
+		c := input[end]
+		switch {
+		case c >= '0' && c <= '9', hex && (c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'), c == '_':
+		case !hex && c == '.':
+			kind = tokFloat
+		case !hex && (c == 'e' || c == 'E'):
+			kind = tokFloat
+			if end+1 < len(input) && (input[end+1] == '+' || input[end+1] == '-') {
+				end++
+			}
+		default:
+			ps.offset = end
+			return token{kind: kind, text: input[start:end], pos: start}
+		}
+		end++
+	}
+	ps.offset = end
+	return token{kind: kind, text: input[start:end], pos: start}
+}
+
+// Синтаксический анализ
+
+// parseState - состояние разбора одной входной строки
+type parseState struct {
+	parser *Parser
+	input  string
+	offset int
+	tok    token
+}
+
+func (ps *parseState) next() {
+	ps.tok = ps.scan()
+}
+
+func (ps *parseState) is(op string) bool {
+	return ps.tok.kind == tokOp && ps.tok.text == op
+}
+
+func (ps *parseState) expect(op string) {
+	if !ps.is(op) {
+		ps.failf(ps.tok.pos, "expected %q, found %s", op, ps.tok)
+	}
+	ps.next()
+}
+
+func (ps *parseState) ident() token {
+	if ps.tok.kind != tokIdent {
+		ps.failf(ps.tok.pos, "expected name, found %s", ps.tok)
+	}
+	res := ps.tok
+	ps.next()
+	return res
+}
+
+// fail прерывает разбор ошибкой в позиции pos
+func (ps *parseState) fail(pos int, err error) {
+	line := 1 + strings.Count(ps.input[:pos], "\n")
+	column := pos - strings.LastIndex(ps.input[:pos], "\n")
+	panic(&ParseError{Line: line, Column: column, Err: err})
+}
+
+func (ps *parseState) failf(pos int, format string, args ...interface{}) {
+	ps.fail(pos, fmt.Errorf(format, args...))
+}
+
+// declarations разбирает объявления "var x, y T" и "func f(T, ...) T"
+func (ps *parseState) declarations() {
+	for {
+		switch {
+		case ps.is(";"):
+			ps.next()
+		case ps.tok.kind == tokIdent && ps.tok.text == "var":
+			ps.next()
+			names := []token{ps.ident()}
+			for ps.is(",") {
+				ps.next()
+				names = append(names, ps.ident())
+			}
+			tpe, sort := ps.typeName()
+			for _, name := range names {
+				ps.declareVariable(name, &SymbolicVariable{Name: name.text, ExprType: tpe, Sort: sort})
+			}
+		case ps.tok.kind == tokIdent && ps.tok.text == "func":
+			ps.next()
+			name := ps.ident()
+			ps.expect("(")
+			var params []ExpressionType
+			for !ps.is(")") {
+				if len(params) > 0 {
+					ps.expect(",")
+				}
+				params = append(params, ps.scalarType())
+			}
+			ps.next()
+			result := ps.scalarType()
+			function, err := NewFunctionDeclarationChecked(name.text, result, params...)
+			if err != nil {
+				ps.fail(name.pos, err)
+			}
+			if old, ok := ps.parser.Functions[name.text]; ok && old.String() != function.String() {
+				ps.failf(name.pos, "function %s redeclared as %s", old, function)
+			}
+			ps.parser.Functions[name.text] = function
+		default:
+			return
+		}
+	}
+}
+
+func (ps *parseState) declareVariable(name token, v *SymbolicVariable) {
+	if old, ok := ps.parser.Variables[v.Name]; ok && !old.Equal(v) {
+		ps.failf(name.pos, "variable %s redeclared with another type", v.Name)
+	}
+	ps.parser.Variables[v.Name] = v
+}
+
+// typeName разбирает тип переменной: имя скалярного типа, "[]byte" или сорт массива "[int]uint8"
+func (ps *parseState) typeName() (ExpressionType, ArraySort) {
+	if !ps.is("[") {
+		return ps.scalarType(), ArraySort{}
+	}
+
+	ps.next()
+	if ps.is("]") {
+		ps.next()
+		if name := ps.ident(); name.text != "byte" && name.text != "uint8" {
+			ps.failf(name.pos, "unsupported slice type []%s", name.text)
+		}
+		return BytesType, ArraySort{}
+	}
+	index := ps.scalarType()
+	ps.expect("]")
+	return ArrayType, ArraySort{Index: index, Element: ps.scalarType()}
+}
+
+// scalarType разбирает имя типа; кроме имён ExpressionType допускаются float64, byte и rune
+func (ps *parseState) scalarType() ExpressionType {
+	pos := ps.tok.pos
+	if ps.is("[") {
+		tpe, _ := ps.typeName()
+		if tpe != BytesType {
+			ps.failf(pos, "array type is not allowed here")
+		}
+		return tpe
+	}
+	name := ps.ident()
+	tpe, ok := lookupType(name.text)
+	if !ok || tpe == ArrayType || tpe == ObjectType || tpe == ReferenceType {
+		ps.failf(pos, "unknown type %s", name.text)
+	}
+	return tpe
+}
+
+func lookupType(name string) (ExpressionType, bool) {
+	switch name {
+	case "float64":
+		return FloatType, true
+	case "byte":
+		return ByteType, true
+	case "rune":
+		return RuneType, true
+	}
+	tpe, err := parseType(name)
+	return tpe, err == nil
+}
+
+// Узлы синтаксического дерева. Литералы остаются нетипизированными до построения
+// выражения, когда становится известен тип контекста.
+type (
+	astNode interface{ position() int }
+
+	astIdent struct {
+		pos  int
+		name string
+	}
+	astLiteral struct {
+		pos  int
+		kind tokenKind // tokInt, tokFloat или tokString
+		text string    // текст литерала со знаком
+	}
+	astUnary struct {
+		pos     int
+		op      string
+		operand astNode
+	}
+	astBinary struct {
+		pos         int
+		op          string
+		left, right astNode
+	}
+	// astLogical - цепочка a && b && c, которая становится одной n-арной операцией
+	astLogical struct {
+		pos      int
+		op       string
+		operands []astNode
+	}
+	astConditional struct {
+		pos             int
+		cond, then, els astNode
+	}
+	// astIndex - чтение a[i] или запись a[i := v], если value не nil
+	astIndex struct {
+		pos          int
+		array, index astNode
+		value        astNode
+	}
+	astCall struct {
+		pos  int
+		name string
+		args []astNode
+	}
+)
+
+func (n *astIdent) position() int       { return n.pos }
+func (n *astLiteral) position() int     { return n.pos }
+func (n *astUnary) position() int       { return n.pos }
+func (n *astBinary) position() int      { return n.pos }
+func (n *astLogical) position() int     { return n.pos }
+func (n *astConditional) position() int { return n.pos }
+func (n *astIndex) position() int       { return n.pos }
+func (n *astCall) position() int        { return n.pos }
+
+// Уровни приоритета бинарных операторов, от слабого к сильному
+var precedence = map[string]int{
+	"=>": 1,
+	"||": 2,
+	"&&": 3,
+	"==": 4, "!=": 4, "<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5, "|": 5, "^": 5,
+	"*": 6, "/": 6, "%": 6, "<<": 6, ">>": 6, "&": 6,
+}
+
+// expression разбирает условное выражение c ? a : b или бинарное выражение
+func (ps *parseState) expression() astNode {
+	cond := ps.binary(1)
+	if !ps.is("?") {
+		return cond
+	}
+	pos := ps.tok.pos
+	ps.next()
+	then := ps.expression()
+	ps.expect(":")
+	return &astConditional{pos: pos, cond: cond, then: then, els: ps.expression()}
+}
+
+// binary разбирает бинарные операторы с приоритетом не ниже level
+func (ps *parseState) binary(level int) astNode {
+	left := ps.unary()
+	for {
+		op := ps.tok.text
+		prec, ok := precedence[op]
+		if ps.tok.kind != tokOp || !ok || prec < level {
+			return left
+		}
+		pos := ps.tok.pos
+		ps.next()
+
+		switch op {
+		case "=>":
+			// Импликация правоассоциативна
+			left = &astLogical{pos: pos, op: op, operands: []astNode{left, ps.binary(prec)}}
+		case "&&", "||":
+			operands := []astNode{left, ps.binary(prec + 1)}
+			for ps.is(op) {
+				ps.next()
+				operands = append(operands, ps.binary(prec+1))
+			}
+			left = &astLogical{pos: pos, op: op, operands: operands}
+		default:
+			left = &astBinary{pos: pos, op: op, left: left, right: ps.binary(prec + 1)}
+		}
+	}
+}
+
+// unary разбирает префиксные операторы !, ^ и знак числового литерала
+func (ps *parseState) unary() astNode {
+	pos := ps.tok.pos
+	switch {
+	case ps.is("!"), ps.is("^"):
+		op := ps.tok.text
+		ps.next()
+		return &astUnary{pos: pos, op: op, operand: ps.unary()}
+	case ps.is("-"), ps.is("+"):
+		sign := ps.tok.text
+		ps.next()
+		if ps.tok.kind == tokIdent && ps.tok.text == "Inf" {
+			ps.next()
+			return &astLiteral{pos: pos, kind: tokFloat, text: sign + "Inf"}
+		}
+		if ps.tok.kind != tokInt && ps.tok.kind != tokFloat {
+			ps.failf(pos, "unary %s is supported only for numeric constants", sign)
+		}
+		lit := &astLiteral{pos: pos, kind: ps.tok.kind, text: sign + ps.tok.text}
+		ps.next()
+		return lit
+	}
+	return ps.postfix(ps.primary())
+}
+
+// primary разбирает литерал, имя, вызов или выражение в скобках
+func (ps *parseState) primary() astNode {
+	tok := ps.tok
+	switch tok.kind {
+	case tokInt, tokFloat, tokString:
+		ps.next()
+		return &astLiteral{pos: tok.pos, kind: tok.kind, text: tok.text}
+	case tokIdent:
+		ps.next()
+		if ps.is("(") {
+			return ps.call(tok.pos, tok.text)
+		}
+		// NaN и Inf - нетипизированные константы, если не объявлены переменные с такими именами
+		if _, ok := ps.parser.Variables[tok.text]; !ok && (tok.text == "NaN" || tok.text == "Inf") {
+			return &astLiteral{pos: tok.pos, kind: tokFloat, text: tok.text}
+		}
+		return &astIdent{pos: tok.pos, name: tok.text}
+	}
+
+	switch {
+	case ps.is("("):
+		ps.next()
+		res := ps.expression()
+		ps.expect(")")
+		return res
+	case ps.is("["):
+		// Преобразование []byte(x)
+		ps.next()
+		ps.expect("]")
+		if name := ps.ident(); name.text != "byte" {
+			ps.failf(name.pos, "unsupported slice type []%s", name.text)
+		}
+		if !ps.is("(") {
+			ps.failf(ps.tok.pos, "expected \"(\", found %s", ps.tok)
+		}
+		return ps.call(tok.pos, "[]byte")
+	}
+	ps.failf(tok.pos, "unexpected %s", tok)
+	return nil
+}
+
+func (ps *parseState) call(pos int, name string) astNode {
+	ps.expect("(")
+	var args []astNode
+	for !ps.is(")") {
+		if len(args) > 0 {
+			ps.expect(",")
+		}
+		args = append(args, ps.expression())
+	}
+	ps.next()
+	return &astCall{pos: pos, name: name, args: args}
+}
+
+// postfix разбирает чтения a[i] и записи a[i := v]
+func (ps *parseState) postfix(node astNode) astNode {
+	for ps.is("[") {
+		ps.next()
+		index := &astIndex{pos: node.position(), array: node, index: ps.expression()}
+		if ps.is(":=") {
+			ps.next()
+			index.value = ps.expression()
+		}
+		ps.expect("]")
+		node = index
+	}
+	return node
+}
+
+// Построение выражения
+
+// build строит выражение по узлу. expected - тип контекста для нетипизированных
+// литералов или nil, если контекста нет.
+func (ps *parseState) build(node astNode, expected *ExpressionType) SymbolicExpression {
+	switch n := node.(type) {
+	case *astLiteral:
+		return ps.literal(n, expected)
+	case *astIdent:
+		return ps.identifier(n)
+	case *astUnary:
+		if n.op == "!" {
+			operand := ps.build(n.operand, typeRef(BoolType))
+			return ps.check(n, func() (SymbolicExpression, error) {
+				return NewLogicalOperationChecked([]SymbolicExpression{operand}, NOT)
+			})
+		}
+		operand := ps.build(n.operand, nil)
+		return ps.check(n, func() (SymbolicExpression, error) { return NewUnaryOperationChecked(operand, BNOT) })
+	case *astBinary:
+		return ps.binaryOperation(n)
+	case *astLogical:
+		operands := make([]SymbolicExpression, len(n.operands))
+		for i, operand := range n.operands {
+			operands[i] = ps.build(operand, typeRef(BoolType))
+		}
+		return ps.check(n, func() (SymbolicExpression, error) {
+			return NewLogicalOperationChecked(operands, logicalOperators[n.op])
+		})
+	case *astConditional:
+		cond := ps.build(n.cond, typeRef(BoolType))
+		then, els := ps.unify(n.then, n.els)
+		return ps.check(n, func() (SymbolicExpression, error) { return NewConditionalExpressionChecked(cond, then, els) })
+	case *astIndex:
+		array := ps.build(n.array, nil)
+		sort := ArraySortOf(array)
+		index := ps.build(n.index, &sort.Index)
+		if n.value == nil {
+			return ps.check(n, func() (SymbolicExpression, error) { return NewArraySelectChecked(array, index) })
+		}
+		value := ps.build(n.value, &sort.Element)
+		return ps.check(n, func() (SymbolicExpression, error) { return NewArrayStoreChecked(array, index, value) })
+	case *astCall:
+		return ps.callExpression(n)
+	}
+	panic("unexpected node")
+}
+
+func typeRef(tpe ExpressionType) *ExpressionType {
+	return &tpe
+}
+
+// check вызывает конструктор и прерывает разбор его ошибкой в позиции узла
+func (ps *parseState) check(node astNode, construct func() (SymbolicExpression, error)) SymbolicExpression {
+	res, err := construct()
+	if err != nil {
+		ps.fail(node.position(), err)
+	}
+	return res
+}
+
+// binaryOperation строит бинарную операцию. Литерал получает тип другого операнда,
+// а операнды сдвига строятся без контекста.
+func (ps *parseState) binaryOperation(n *astBinary) SymbolicExpression {
+	op := binaryOperators[n.op]
+	var left, right SymbolicExpression
+	if op == SHL || op == SHR {
+		left = ps.build(n.left, nil)
+		right = ps.build(n.right, nil)
+	} else {
+		left, right = ps.unify(n.left, n.right)
+	}
+	return ps.check(n, func() (SymbolicExpression, error) { return NewBinaryOperationChecked(left, right, op) })
+}
+
+// unify строит два операнда, тип которых должен совпадать: литерал получает тип другого операнда,
+// а если оба операнда - литералы, второй получает тип первого. Порядок согласован с operandStrings.
+func (ps *parseState) unify(a, b astNode) (SymbolicExpression, SymbolicExpression) {
+	_, aLiteral := a.(*astLiteral)
+	if _, bLiteral := b.(*astLiteral); aLiteral && !bLiteral {
+		right := ps.build(b, nil)
+		tpe := right.Type()
+		return ps.build(a, &tpe), right
+	}
+	left := ps.build(a, nil)
+	tpe := left.Type()
+	return left, ps.build(b, &tpe)
+}
+
+// literal строит константу типа контекста или типа по умолчанию
+func (ps *parseState) literal(n *astLiteral, expected *ExpressionType) SymbolicExpression {
+	if n.kind == tokString {
+		value, err := strconv.Unquote(n.text)
+		if err != nil {
+			ps.failf(n.pos, "invalid string literal %s", n.text)
+		}
+		return NewStringConstant(value)
+	}
+
+	tpe := literalType(n.kind == tokFloat, expected)
+	overflow := func() {
+		ps.fail(n.pos, fmt.Errorf("%w: %s overflows %s", ErrValueOutOfRange, n.text, tpe))
+	}
+	text := strings.TrimPrefix(n.text, "+")
+
+	if tpe.IsFloat() {
+		// Текст округляется сразу до точности tpe: промежуточное округление до int64 или float64
+		// теряет знак нуля ("-0") и может дать другое значение float32
+		if n.kind == tokInt && (strings.Contains(text, "0x") || strings.Contains(text, "0X")) {
+			text += "p0"
+		}
+		bits := 64
+		if tpe == Float32Type {
+			bits = 32
+		}
+		value, err := strconv.ParseFloat(text, bits)
+		if errors.Is(err, strconv.ErrRange) {
+			overflow()
+		}
+		if err != nil {
+			ps.failf(n.pos, "invalid number %s", n.text)
+		}
+		return NewTypedFloatConstant(value, tpe)
+	}
+
+	var value int64
+	var err error
+	if tpe.IsSigned() {
+		value, err = strconv.ParseInt(text, 0, tpe.Bits())
+	} else if strings.HasPrefix(text, "-") {
+		overflow()
+	} else {
+		var u uint64
+		u, err = strconv.ParseUint(text, 0, tpe.Bits())
+		value = int64(u)
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		overflow()
+	}
+	if err != nil {
+		ps.failf(n.pos, "invalid number %s", n.text)
+	}
+	return NewTypedIntConstant(value, tpe)
+}
+
+// literalType возвращает тип числового литерала в контексте expected (nil - без контекста):
+// целый литерал принимает целый тип или тип с плавающей точкой, а дробный - только последний
+func literalType(float bool, expected *ExpressionType) ExpressionType {
+	tpe := IntType
+	if float {
+		tpe = FloatType
+	}
+	if expected != nil && (expected.IsFloat() || expected.IsInteger() && !float) {
+		tpe = *expected
+	}
+	return tpe
+}
+
+// identifier строит переменную или булеву константу
+func (ps *parseState) identifier(n *astIdent) SymbolicExpression {
+	if v, ok := ps.parser.Variables[n.name]; ok {
+		return v
+	}
+	if n.name == "true" || n.name == "false" {
+		return NewBoolConstant(n.name == "true")
+	}
+	ps.failf(n.pos, "undeclared variable %s", n.name)
+	return nil
+}
+
+// callExpression строит применение объявленной функции, операцию над строками
+// или преобразование типа T(x)
+func (ps *parseState) callExpression(n *astCall) SymbolicExpression {
+	if function, ok := ps.parser.Functions[n.name]; ok {
+		args := make([]SymbolicExpression, len(n.args))
+		for i, arg := range n.args {
+			var expected *ExpressionType
+			if i < len(function.Params) {
+				expected = &function.Params[i]
+			}
+			args[i] = ps.build(arg, expected)
+		}
+		return ps.check(n, func() (SymbolicExpression, error) { return NewFunctionApplicationChecked(function, args...) })
+	}
+
+	target, isType := lookupType(n.name)
+	if n.name == "[]byte" {
+		target, isType = BytesType, true
+	}
+	isType = isType && target != ArrayType && target != ObjectType && target != ReferenceType
+	if isType && len(n.args) != 1 {
+		ps.fail(n.pos, ErrArgumentCount)
+	}
+	// T(литерал) - типизированная константа, как в Go. Литерал строится сразу с типом T:
+	// без контекста uint64(18446744073709551615) не помещается в int
+	if isType {
+		if lit, ok := n.args[0].(*astLiteral); ok && lit.kind != tokString && literalType(lit.kind == tokFloat, &target) == target {
+			return ps.literal(lit, &target)
+		}
+	}
+
+	args := make([]SymbolicExpression, len(n.args))
+	for i, arg := range n.args {
+		args[i] = ps.build(arg, nil)
+	}
+
+	if isType {
+		// string(b) и []byte(s) - операции FROM_BYTES и TO_BYTES, а не преобразования
+		if target == StringType && args[0].Type() == BytesType {
+			return ps.check(n, func() (SymbolicExpression, error) { return NewStringOperationChecked(FROM_BYTES, args...) })
+		}
+		if target == BytesType && args[0].Type() == StringType {
+			return ps.check(n, func() (SymbolicExpression, error) { return NewStringOperationChecked(TO_BYTES, args...) })
+		}
+		return ps.check(n, func() (SymbolicExpression, error) { return NewConversionChecked(args[0], target) })
+	}
+
+	if op, ok := stringOperators[n.name]; ok {
+		return ps.check(n, func() (SymbolicExpression, error) { return NewStringOperationChecked(op, args...) })
+	}
+	ps.failf(n.pos, "undeclared function %s", n.name)
+	return nil
+}
@@ -0,0 +1,160 @@
+package symbolic
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	x := NewSymbolicVariable("x", IntType)
+	u := NewSymbolicVariable("u", Uint8Type)
+	f := NewSymbolicVariable("f", Float32Type)
+	b := NewSymbolicVariable("b", BoolType)
+	s := NewSymbolicVariable("s", StringType)
+	bs := NewSymbolicVariable("bs", BytesType)
+	a := NewArrayVariable("a", ArraySort{Index: IntType, Element: Uint8Type})
+	renamed := NewSymbolicVariable("f#1.x", IntType)
+	h := NewFunctionDeclaration("hash", Uint32Type, IntType, BoolType)
+
+	p := NewParser(x, u, f, b, s, bs, a, renamed)
+	p.Functions[h.Name] = h
+
+	op := NewBinaryOperation
+	and := func(operands ...SymbolicExpression) SymbolicExpression { return NewLogicalOperation(operands, AND) }
+	not := func(e SymbolicExpression) SymbolicExpression {
+		return NewLogicalOperation([]SymbolicExpression{e}, NOT)
+	}
+	u8 := func(v int64) SymbolicExpression { return NewTypedIntConstant(v, Uint8Type) }
+
+	tests := []SymbolicExpression{
+		op(op(x, NewIntConstant(1), ADD), NewIntConstant(-5), GT),
+		op(u, u8(255), LT),
+		op(u8(1), u, SUB),
+		op(op(u, NewIntConstant(3), SHL), u8(8), EQ),
+		op(f, NewTypedFloatConstant(0.5, Float32Type), MUL),
+		op(x, NewFloatConstant(0.25), ADD),
+		op(f, NewTypedFloatConstant(math.NaN(), Float32Type), NE),
+		op(NewFloatConstant(math.Inf(-1)), NewFloatConstant(1), LT),
+		and(b, not(op(x, renamed, LE)), NewBoolConstant(true)),
+		and(and(b, b), b),
+		NewLogicalOperation([]SymbolicExpression{b, op(x, x, EQ)}, OR),
+		NewLogicalOperation([]SymbolicExpression{b, not(not(b))}, IMPLIES),
+		NewUnaryOperation(u, BNOT),
+		NewConditionalExpression(b, op(x, NewIntConstant(2), MOD), NewIntConstant(0)),
+		NewConditionalExpression(b, u, u8(7)),
+		NewArraySelect(NewArrayStore(a, x, u8(200)), NewIntConstant(3)),
+		NewFunctionApplication(h, x, b),
+		op(s, NewStringConstant("a\"b\n\xff"), ADD),
+		NewStringOperation(SLICE, bs, NewIntConstant(1), NewStringOperation(LEN, bs)),
+		NewStringOperation(HAS_PREFIX, NewStringOperation(FROM_BYTES, NewStringOperation(TO_BYTES, s)), NewStringConstant("go")),
+		op(NewStringOperation(AT, s, NewIntConstant(0)), u8(97), EQ),
+		op(NewStringOperation(INDEX, s, NewStringConstant("x")), NewIntConstant(-1), NE),
+		NewConversion(NewConversion(u, Int32Type), FloatType),
+		NewConversion(NewIntConstant(300), Uint8Type),
+		NewConversion(s, StringType),
+		NewConversion(bs, BytesType),
+		op(NewConversion(f, Int64Type), NewTypedIntConstant(math.MinInt64, Int64Type), GE),
+		op(NewConversion(x, Uint64Type), NewTypedIntConstant(-1, Uint64Type), LT),
+		// Константы, тип которых не следует из контекста, и точные значения с плавающей точкой
+		NewFloatConstant(1e-9),
+		NewFloatConstant(0.1234567),
+		NewFloatConstant(2),
+		NewTypedFloatConstant(0.1, Float32Type),
+		NewConversion(NewTypedIntConstant(5, Uint8Type), IntType),
+		NewTypedIntConstant(7, Uint16Type),
+		op(u8(1), u8(2), ADD),
+		op(x, NewFloatConstant(2), ADD),
+		op(f, NewIntConstant(1), ADD),
+		op(NewIntConstant(1), NewFloatConstant(0.5), ADD),
+		op(u8(1), NewTypedIntConstant(3, UintType), SHL),
+		NewConditionalExpression(b, NewTypedIntConstant(1, Int64Type), NewTypedIntConstant(2, Int64Type)),
+		NewFunctionApplication(h, NewIntConstant(1), b),
+		NewTypedIntConstant(-1, Uint64Type),
+		NewTypedFloatConstant(math.Copysign(0, -1), FloatType),
+		NewTypedFloatConstant(16777217, Float32Type),
+		op(f, NewTypedFloatConstant(math.Copysign(0, -1), Float32Type), LT),
+	}
+
+	for _, expr := range tests {
+		t.Run(expr.String(), func(t *testing.T) {
+			parsed, err := p.Parse(expr.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !parsed.Equal(expr) {
+				t.Errorf("expected %s, got %s", expr, parsed)
+			}
+		})
+	}
+}
+
+func TestParseSyntax(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"declarations", "var x, y int; x > 0 && y < 10", "((x > 0) && (y < 10))"},
+		{"precedence", "var x int\nx + 2 * x << 1 == 4 || false", "(((x + ((2 * x) << 1)) == 4) || false)"},
+		{"implication", "var a, b, c bool; a => b => !c", "(a => (b => !c))"},
+		{"ternary", "var c bool; var x uint16; c ? x + 1 : 0x10", "(c ? (x + 1) : 16)"},
+		{"array declaration", "var a [uint8]int; var i uint8; a[i := 1][2]", "a[i := 1][2]"},
+		{"function declaration", "func h(int, int) bool; h(1, 2) && h(2, 1)", "(h(1, 2) && h(2, 1))"},
+		{"byte conversion", `[]byte("ab")`, `bytes("ab")`},
+		{"bytes declaration", `var b []byte; len(b) + 1`, "(len(b) + 1)"},
+		{"float32 single rounding", "float32(16777217.000000001)", "float32(1.6777218e+07)"},
+		{"negative zero", "var f float32; f < -0", "(f < -0)"},
+		{"raw string", "hasSuffix(`a\\b`, \"b\")", `hasSuffix("a\\b", "b")`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := expr.String(); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		line, column int
+		expected     error
+	}{
+		{"undeclared variable", "var x int; x + y", 1, 16, nil},
+		{"incompatible types", "var x int; var b bool\n(x + 1) + b", 2, 9, ErrIncompatibleTypes},
+		{"logical operand", "var x int; x && true", 1, 14, ErrIncorrectType},
+		{"constant overflow", "var u uint8; u < 256", 1, 18, ErrValueOutOfRange},
+		{"negative unsigned", "var u uint8; u == -1", 1, 19, ErrValueOutOfRange},
+		{"argument count", "func f(int) int; f(1, 2)", 1, 18, ErrArgumentCount},
+		{"unexpected token", "(1 + 2))", 1, 8, nil},
+		{"missing parenthesis", "(1 + 2", 1, 7, nil},
+		{"unknown type", "var x complex128; x", 1, 7, nil},
+		{"unterminated string", `len("abc)`, 1, 5, nil},
+		{"empty", "var x int;", 1, 11, nil},
+		{"redeclaration", "var x int; var x bool; x", 1, 16, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected *ParseError, got %v", err)
+			}
+			if parseErr.Line != tt.line || parseErr.Column != tt.column {
+				t.Errorf("expected error at %d:%d, got %v", tt.line, tt.column, err)
+			}
+			if tt.expected != nil && !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
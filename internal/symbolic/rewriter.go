@@ -0,0 +1,179 @@
+package symbolic
+
+import "slices"
+
+// Children возвращает непосредственные подвыражения в порядке полей узла.
+// У переменных, констант и ссылок подвыражений нет.
+func Children(expr SymbolicExpression) []SymbolicExpression {
+	switch e := expr.(type) {
+	case *BinaryOperation:
+		return []SymbolicExpression{e.Left, e.Right}
+	case *UnaryOperation:
+		return []SymbolicExpression{e.Left}
+	case *LogicalOperation:
+		return e.Operands
+	case *ConditionalExpression:
+		return []SymbolicExpression{e.Condition, e.Then, e.Else}
+	case *ArraySelect:
+		return []SymbolicExpression{e.Array, e.Index}
+	case *ArrayStore:
+		return []SymbolicExpression{e.Array, e.Index, e.Value}
+	case *FunctionApplication:
+		return e.Args
+	case *StringOperation:
+		return e.Operands
+	case *Conversion:
+		return []SymbolicExpression{e.Operand}
+	default:
+		return nil
+	}
+}
+
+// WithChildren строит узел того же вида, что expr, с подвыражениями children.
+// Узел создаётся конструктором своего вида, поэтому подвыражения неподходящих
+// типов приводят к панике. Если подвыражения не изменились, возвращается сам expr.
+func WithChildren(expr SymbolicExpression, children []SymbolicExpression) SymbolicExpression {
+	if slices.Equal(Children(expr), children) {
+		return expr
+	}
+
+	switch e := expr.(type) {
+	case *BinaryOperation:
+		return NewBinaryOperation(children[0], children[1], e.Operator)
+	case *UnaryOperation:
+		return NewUnaryOperation(children[0], e.Operator)
+	case *LogicalOperation:
+		return NewLogicalOperation(children, e.Operator)
+	case *ConditionalExpression:
+		return NewConditionalExpression(children[0], children[1], children[2])
+	case *ArraySelect:
+		return NewArraySelect(children[0], children[1])
+	case *ArrayStore:
+		return NewArrayStore(children[0], children[1], children[2])
+	case *FunctionApplication:
+		return NewFunctionApplication(e.Function, children...)
+	case *StringOperation:
+		return NewStringOperation(e.Operator, children...)
+	case *Conversion:
+		return NewConversion(children[0], e.Target)
+	}
+	panic("incorrect number of arguments")
+}
+
+// Rewriter переписывает дерево выражения, перестраивая только изменившиеся узлы.
+// Переписывание сохраняет типы: если обработчик вернул выражение другого типа
+// (или массив другого сорта), Rewrite паникует.
+type Rewriter struct {
+	// Pre вызывается для узла до обхода его подвыражений (сверху вниз).
+	// Обход продолжается в подвыражения возвращённого узла. Если skip равен true,
+	// возвращённый узел считается окончательным: его подвыражения не обходятся и Post не вызывается.
+	Pre func(expr SymbolicExpression) (res SymbolicExpression, skip bool)
+	// Post вызывается для узла, перестроенного из уже переписанных подвыражений (снизу вверх)
+	Post func(expr SymbolicExpression) SymbolicExpression
+}
+
+// Rewrite применяет обработчики ко всем узлам expr и возвращает результат
+func (r Rewriter) Rewrite(expr SymbolicExpression) SymbolicExpression {
+	res := expr
+	if r.Pre != nil {
+		var skip bool
+		res, skip = r.Pre(expr)
+		checkSameType(expr, res)
+		if skip {
+			return res
+		}
+	}
+
+	children := Children(res)
+	if len(children) > 0 {
+		rewritten := make([]SymbolicExpression, len(children))
+		for i, child := range children {
+			rewritten[i] = r.Rewrite(child)
+		}
+		res = WithChildren(res, rewritten)
+	}
+
+	if r.Post != nil {
+		post := r.Post(res)
+		checkSameType(res, post)
+		res = post
+	}
+	return res
+}
+
+func checkSameType(before, after SymbolicExpression) {
+	if before.Type() != after.Type() ||
+		before.Type() == ArrayType && ArraySortOf(before) != ArraySortOf(after) {
+		panic("incompatible types")
+	}
+}
+
+// RewriteBottomUp применяет fn к каждому узлу после переписывания его подвыражений
+func RewriteBottomUp(expr SymbolicExpression, fn func(SymbolicExpression) SymbolicExpression) SymbolicExpression {
+	return Rewriter{Post: fn}.Rewrite(expr)
+}
+
+// RewriteTopDown применяет fn к каждому узлу до обхода его подвыражений,
+// а затем переписывает подвыражения результата
+func RewriteTopDown(expr SymbolicExpression, fn func(SymbolicExpression) SymbolicExpression) SymbolicExpression {
+	return Rewriter{Pre: func(e SymbolicExpression) (SymbolicExpression, bool) {
+		return fn(e), false
+	}}.Rewrite(expr)
+}
+
+// Walk обходит выражение сверху вниз, пока fn возвращает true.
+// Если fn вернула false, подвыражения узла не посещаются.
+func Walk(expr SymbolicExpression, fn func(SymbolicExpression) bool) {
+	if !fn(expr) {
+		return
+	}
+	for _, child := range Children(expr) {
+		Walk(child, fn)
+	}
+}
+
+// Substitute заменяет переменные выражениями из bindings по имени переменной.
+// Подставляемое выражение должно иметь тип переменной. Подставленные выражения
+// повторно не обходятся, так что x -> x + 1 подставляется один раз.
+func Substitute(expr SymbolicExpression, bindings map[string]SymbolicExpression) SymbolicExpression {
+	return Rewriter{Pre: func(e SymbolicExpression) (SymbolicExpression, bool) {
+		if v, ok := e.(*SymbolicVariable); ok {
+			if value, ok := bindings[v.Name]; ok {
+				return value, true
+			}
+		}
+		return e, false
+	}}.Rewrite(expr)
+}
+
+// Rename переименовывает все переменные выражения функцией rename, сохраняя их типы.
+// Например, при подстановке тела вызываемой функции её переменным можно дать
+// уникальные имена: Rename(expr, func(name string) string { return "f#1." + name }).
+func Rename(expr SymbolicExpression, rename func(name string) string) SymbolicExpression {
+	return RewriteBottomUp(expr, func(e SymbolicExpression) SymbolicExpression {
+		v, ok := e.(*SymbolicVariable)
+		if !ok {
+			return e
+		}
+		if name := rename(v.Name); name != v.Name {
+			return &SymbolicVariable{Name: name, ExprType: v.ExprType, Sort: v.Sort}
+		}
+		return e
+	})
+}
+
+// FreeVariables возвращает переменные выражения в порядке первого вхождения,
+// по одной на каждое имя. Связывающих конструкций в выражениях нет,
+// поэтому свободны все переменные. Тип и сорт переменной доступны в её полях.
+func FreeVariables(expr SymbolicExpression) []*SymbolicVariable {
+	var res []*SymbolicVariable
+	seen := map[string]bool{}
+	Walk(expr, func(e SymbolicExpression) bool {
+		if v, ok := e.(*SymbolicVariable); ok && !seen[v.Name] {
+			seen[v.Name] = true
+			res = append(res, v)
+		}
+		return true
+	})
+	return res
+}
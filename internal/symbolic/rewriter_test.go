@@ -0,0 +1,97 @@
+package symbolic
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRewrite(t *testing.T) {
+	x := NewSymbolicVariable("x", IntType)
+	y := NewSymbolicVariable("y", IntType)
+	expr := NewBinaryOperation(NewBinaryOperation(x, NewIntConstant(2), MUL), y, ADD)
+
+	// Снизу вверх: каждая константа увеличивается на 1, затем умножение заменяется сложением
+	order := []string{}
+	res := RewriteBottomUp(expr, func(e SymbolicExpression) SymbolicExpression {
+		order = append(order, e.String())
+		switch e := e.(type) {
+		case *IntConstant:
+			return NewIntConstant(e.Value + 1)
+		case *BinaryOperation:
+			if e.Operator == MUL {
+				return NewBinaryOperation(e.Left, e.Right, ADD)
+			}
+		}
+		return e
+	})
+	if got := res.String(); got != "((x + 3) + y)" {
+		t.Errorf("bottom-up rewrite gave %s", got)
+	}
+	if expected := []string{"x", "2", "(x * 3)", "y", "((x + 3) + y)"}; !slices.Equal(order, expected) {
+		t.Errorf("bottom-up order %v, expected %v", order, expected)
+	}
+
+	// Сверху вниз: подвыражения результата тоже переписываются
+	order = order[:0]
+	res = RewriteTopDown(expr, func(e SymbolicExpression) SymbolicExpression {
+		order = append(order, e.String())
+		return e
+	})
+	if res != expr {
+		t.Errorf("identity rewrite should return the same instance")
+	}
+	if expected := []string{"((x * 2) + y)", "(x * 2)", "x", "2", "y"}; !slices.Equal(order, expected) {
+		t.Errorf("top-down order %v, expected %v", order, expected)
+	}
+
+	// Подвыражения, не затронутые переписыванием, разделяются с исходным выражением
+	res = Substitute(expr, map[string]SymbolicExpression{"y": NewIntConstant(1)})
+	if res.(*BinaryOperation).Left != expr.Left {
+		t.Errorf("unchanged subexpression was rebuilt")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("rewrite changing the type of a node should panic")
+		}
+	}()
+	RewriteBottomUp(expr, func(e SymbolicExpression) SymbolicExpression {
+		if e == x {
+			return NewBoolConstant(true)
+		}
+		return e
+	})
+}
+
+func TestSubstituteRenameFreeVariables(t *testing.T) {
+	x := NewSymbolicVariable("x", IntType)
+	b := NewSymbolicVariable("b", BoolType)
+	a := NewArrayVariable("a", ArraySort{Element: Uint8Type})
+	expr := Ite(b, NewBinaryOperation(x, NewIntConstant(1), ADD), NewConversion(NewArraySelect(a, x), IntType))
+
+	res := Substitute(expr, map[string]SymbolicExpression{"x": NewBinaryOperation(x, NewIntConstant(1), ADD)})
+	if got := res.String(); got != "(b ? ((x + 1) + 1) : int(a[(x + 1)]))" {
+		t.Errorf("substitution gave %s", got)
+	}
+
+	renamed := Rename(expr, func(name string) string { return "f#1." + name })
+	if got := renamed.String(); got != "(f#1.b ? (f#1.x + 1) : int(f#1.a[f#1.x]))" {
+		t.Errorf("renaming gave %s", got)
+	}
+	if sort := ArraySortOf(FreeVariables(renamed)[2]); sort.Element != Uint8Type {
+		t.Errorf("renaming lost array sort: %s", sort)
+	}
+
+	vars := FreeVariables(expr)
+	if len(vars) != 3 {
+		t.Fatalf("expected 3 free variables, got %d", len(vars))
+	}
+	for i, expected := range []struct {
+		name string
+		tpe  ExpressionType
+	}{{"b", BoolType}, {"x", IntType}, {"a", ArrayType}} {
+		if vars[i].Name != expected.name || vars[i].Type() != expected.tpe {
+			t.Errorf("free variable %d is %s %s, expected %s %s", i, vars[i].Name, vars[i].Type(), expected.name, expected.tpe)
+		}
+	}
+}
@@ -0,0 +1,662 @@
+package symbolic
+
+import (
+	"math"
+	"slices"
+	"strings"
+)
+
+// Simplify возвращает выражение, эквивалентное expr, но, как правило, меньшего размера.
+// Упрощение выполняется снизу вверх и включает:
+//   - свёртку констант с семантикой Go (переполнение целых, деление с округлением к нулю, IEEE 754);
+//   - алгебраические тождества над целыми: x+0, x*1, x*0, x-x, x&x, x^x, ^^x и подобные;
+//   - объединение констант в цепочках x+c1+c2, x*c1*c2;
+//   - выравнивание вложенных AND/OR, удаление true из конъюнкций (false из дизъюнкций)
+//     и повторяющихся операндов, поглощение x && !x;
+//   - снятие двойного отрицания и внесение отрицания в сравнения: !(a < b) -> a >= b;
+//   - канонический порядок операндов коммутативных операций и сравнений:
+//     константа справа, остальные операнды упорядочены по строковому представлению.
+//
+// Тождества, неверные для чисел с плавающей точкой из-за NaN и -0, к ним не применяются.
+func Simplify(expr SymbolicExpression) SymbolicExpression {
+	return simplifier{}.simplify(expr)
+}
+
+// simplifier реализует Visitor, каждый метод которого возвращает упрощённое выражение
+type simplifier struct{}
+
+func (s simplifier) simplify(expr SymbolicExpression) SymbolicExpression {
+	// Accept ссылки обходит объект в памяти, а не саму ссылку
+	if _, ok := expr.(*Ref); ok {
+		return expr
+	}
+	return expr.Accept(s).(SymbolicExpression)
+}
+
+func (s simplifier) VisitVariable(expr *SymbolicVariable) interface{} {
+	return expr
+}
+
+func (s simplifier) VisitIntConstant(expr *IntConstant) interface{} {
+	return expr
+}
+
+func (s simplifier) VisitBoolConstant(expr *BoolConstant) interface{} {
+	return expr
+}
+
+func (s simplifier) VisitFloatConstant(expr *FloatConstant) interface{} {
+	return expr
+}
+
+func (s simplifier) VisitStringConstant(expr *StringConstant) interface{} {
+	return expr
+}
+
+func (s simplifier) VisitBinaryOperation(expr *BinaryOperation) interface{} {
+	return s.binary(s.simplify(expr.Left), s.simplify(expr.Right), expr.Operator)
+}
+
+// binary упрощает бинарную операцию над уже упрощёнными операндами
+func (s simplifier) binary(left, right SymbolicExpression, op BinaryOperator) SymbolicExpression {
+	if res := foldBinary(left, right, op); res != nil {
+		return res
+	}
+
+	sameType := left.Type() == right.Type()
+	if sameType && (isCommutative(op, left.Type()) || isComparison(op)) && less(right, left) {
+		left, right = right, left
+		op = swapOperator(op)
+	}
+
+	if !left.Type().IsInteger() || !sameType && op != SHL && op != SHR {
+		if isComparison(op) && !left.Type().IsFloat() && same(left, right) {
+			return NewBoolConstant(op == EQ || op == LE || op == GE)
+		}
+		return NewBinaryOperation(left, right, op)
+	}
+
+	if res := integerIdentity(left, right, op); res != nil {
+		return res
+	}
+
+	// (x op c1) op c2 -> x op (c1 op c2): целочисленная арифметика с переполнением ассоциативна
+	if inner, ok := left.(*BinaryOperation); ok && inner.Operator == op && isAssociative(op) {
+		if c1, ok := inner.Right.(*IntConstant); ok {
+			if c2, ok := right.(*IntConstant); ok && inner.Left.Type() == left.Type() {
+				return s.binary(inner.Left, foldBinary(c1, c2, op), op)
+			}
+		}
+	}
+
+	return NewBinaryOperation(left, right, op)
+}
+
+// integerIdentity применяет алгебраические тождества к целочисленной операции
+// с упорядоченными операндами (константа, если есть, справа)
+func integerIdentity(left, right SymbolicExpression, op BinaryOperator) SymbolicExpression {
+	tpe := left.Type()
+	zero := NewTypedIntConstant(0, tpe)
+
+	switch op {
+	case ADD, SUB, BOR, BXOR, SHL, SHR:
+		if isIntValue(right, 0) {
+			return left
+		}
+	case MUL, DIV:
+		if isIntValue(right, 1) {
+			return left
+		}
+	}
+
+	switch op {
+	case MUL, BAND:
+		if isIntValue(right, 0) {
+			return zero
+		}
+	case MOD:
+		if isIntValue(right, 1) {
+			return zero
+		}
+	case SHL, SHR:
+		if isIntValue(left, 0) {
+			return left
+		}
+	}
+
+	if same(left, right) {
+		switch op {
+		case SUB, BXOR:
+			return zero
+		case BAND, BOR:
+			return left
+		case EQ, LE, GE:
+			return NewBoolConstant(true)
+		case NE, LT, GT:
+			return NewBoolConstant(false)
+		}
+	}
+
+	if op == BAND && isIntValue(right, -1) {
+		return left
+	}
+	return nil
+}
+
+func (s simplifier) VisitUnaryOperation(expr *UnaryOperation) interface{} {
+	operand := s.simplify(expr.Left)
+
+	if c, ok := operand.(*IntConstant); ok {
+		return NewTypedIntConstant(^c.Value, c.Type())
+	}
+	if inner, ok := operand.(*UnaryOperation); ok && inner.Operator == BNOT {
+		return inner.Left
+	}
+	return NewUnaryOperation(operand, expr.Operator)
+}
+
+func (s simplifier) VisitLogicalOperation(expr *LogicalOperation) interface{} {
+	operands := make([]SymbolicExpression, len(expr.Operands))
+	for i, operand := range expr.Operands {
+		operands[i] = s.simplify(operand)
+	}
+
+	switch expr.Operator {
+	case NOT:
+		return negate(operands[0])
+	case IMPLIES:
+		return s.implies(operands[0], operands[1])
+	default:
+		return junction(operands, expr.Operator)
+	}
+}
+
+func (s simplifier) implies(premise, conclusion SymbolicExpression) SymbolicExpression {
+	if c, ok := premise.(*BoolConstant); ok {
+		if c.Value {
+			return conclusion
+		}
+		return NewBoolConstant(true)
+	}
+	if c, ok := conclusion.(*BoolConstant); ok {
+		if c.Value {
+			return conclusion
+		}
+		return negate(premise)
+	}
+	if same(premise, conclusion) {
+		return NewBoolConstant(true)
+	}
+	return NewLogicalOperation([]SymbolicExpression{premise, conclusion}, IMPLIES)
+}
+
+// negate строит отрицание упрощённого выражения, внося его в константы,
+// двойные отрицания и сравнения (кроме <, <=, >, >= над числами с плавающей точкой)
+func negate(expr SymbolicExpression) SymbolicExpression {
+	switch expr := expr.(type) {
+	case *BoolConstant:
+		return NewBoolConstant(!expr.Value)
+	case *LogicalOperation:
+		if expr.Operator == NOT {
+			return expr.Operands[0]
+		}
+	case *BinaryOperation:
+		// Для чисел с плавающей точкой !(a < b) не равно a >= b, если один из операндов NaN
+		if expr.Operator == EQ || expr.Operator == NE ||
+			isComparison(expr.Operator) && !expr.Left.Type().IsFloat() && !expr.Right.Type().IsFloat() {
+			return NewBinaryOperation(expr.Left, expr.Right, negateOperator(expr.Operator))
+		}
+	}
+	return NewLogicalOperation([]SymbolicExpression{expr}, NOT)
+}
+
+// junction строит AND или OR из упрощённых операндов: вложенные операции того же вида
+// выравниваются, нейтральные и повторяющиеся операнды отбрасываются, а поглощающая
+// константа или пара x, !x даёт константу. Порядок операндов сохраняется.
+func junction(operands []SymbolicExpression, op LogicalOperator) SymbolicExpression {
+	absorbing := op == OR
+
+	var res []SymbolicExpression
+	seen := map[uint64][]SymbolicExpression{}
+	contains := func(expr SymbolicExpression) bool {
+		return slices.ContainsFunc(seen[expr.Hash()], expr.Equal)
+	}
+	var add func(expr SymbolicExpression) bool
+	add = func(expr SymbolicExpression) bool {
+		if inner, ok := expr.(*LogicalOperation); ok && inner.Operator == op {
+			for _, operand := range inner.Operands {
+				if !add(operand) {
+					return false
+				}
+			}
+			return true
+		}
+		if c, ok := expr.(*BoolConstant); ok {
+			return c.Value != absorbing
+		}
+
+		if contains(expr) {
+			return true
+		}
+		if contains(negate(expr)) {
+			return false
+		}
+		seen[expr.Hash()] = append(seen[expr.Hash()], expr)
+		res = append(res, expr)
+		return true
+	}
+
+	for _, operand := range operands {
+		if !add(operand) {
+			return NewBoolConstant(absorbing)
+		}
+	}
+
+	switch len(res) {
+	case 0:
+		return NewBoolConstant(!absorbing)
+	case 1:
+		return res[0]
+	default:
+		return NewLogicalOperation(res, op)
+	}
+}
+
+func (s simplifier) VisitConditionalExpression(expr *ConditionalExpression) interface{} {
+	return Ite(s.simplify(expr.Condition), s.simplify(expr.Then), s.simplify(expr.Else))
+}
+
+func (s simplifier) VisitArraySelect(expr *ArraySelect) interface{} {
+	return selectFrom(s.simplify(expr.Array), s.simplify(expr.Index))
+}
+
+// selectFrom упрощает чтение из цепочки записей: чтение по только что записанному
+// индексу даёт записанное значение, а запись по другому константному индексу пропускается
+func selectFrom(array, index SymbolicExpression) SymbolicExpression {
+	if store, ok := array.(*ArrayStore); ok {
+		if same(store.Index, index) {
+			return store.Value
+		}
+		if isConstant(store.Index) && isConstant(index) {
+			return selectFrom(store.Array, index)
+		}
+	}
+	return NewArraySelect(array, index)
+}
+
+func (s simplifier) VisitArrayStore(expr *ArrayStore) interface{} {
+	return NewArrayStore(s.simplify(expr.Array), s.simplify(expr.Index), s.simplify(expr.Value))
+}
+
+func (s simplifier) VisitFunctionApplication(expr *FunctionApplication) interface{} {
+	args := make([]SymbolicExpression, len(expr.Args))
+	for i, arg := range expr.Args {
+		args[i] = s.simplify(arg)
+	}
+	return NewFunctionApplication(expr.Function, args...)
+}
+
+func (s simplifier) VisitConversion(expr *Conversion) interface{} {
+	operand := s.simplify(expr.Operand)
+	source, target := operand.Type(), expr.Target
+	if source == target {
+		return operand
+	}
+
+	switch c := operand.(type) {
+	case *IntConstant:
+		if target.IsInteger() {
+			return NewTypedIntConstant(c.Value, target)
+		}
+		// Целые до 2^53 представимы в float64 точно, так что округление происходит один раз
+		if source.IsSigned() && c.Value > -1<<53 && c.Value < 1<<53 {
+			return NewTypedFloatConstant(float64(c.Value), target)
+		}
+		if !source.IsSigned() && uint64(c.Value) < 1<<53 {
+			return NewTypedFloatConstant(float64(uint64(c.Value)), target)
+		}
+	case *FloatConstant:
+		if target.IsFloat() {
+			return NewTypedFloatConstant(c.Value, target)
+		}
+		// Результат преобразования вне диапазона целевого типа не определён и не сворачивается
+		if truncated := math.Trunc(c.Value); inRange(truncated, target) {
+			if target.IsSigned() {
+				return NewTypedIntConstant(int64(truncated), target)
+			}
+			return NewTypedIntConstant(int64(uint64(truncated)), target)
+		}
+	case *Conversion:
+		// Расширение и обратное сужение целого: int8(int(x)) -> x
+		inner := c.Operand.Type()
+		if inner == target && inner.IsInteger() && source.IsInteger() && source.Bits() >= inner.Bits() {
+			return c.Operand
+		}
+	}
+
+	return NewConversion(operand, target)
+}
+
+func (s simplifier) VisitStringOperation(expr *StringOperation) interface{} {
+	operands := make([]SymbolicExpression, len(expr.Operands))
+	for i, operand := range expr.Operands {
+		operands[i] = s.simplify(operand)
+	}
+
+	if res := foldString(operands, expr.Operator); res != nil {
+		return res
+	}
+	return NewStringOperation(expr.Operator, operands...)
+}
+
+// foldString сворачивает операцию над строковыми константами и взаимно обратные преобразования
+func foldString(operands []SymbolicExpression, op StringOperator) SymbolicExpression {
+	if inner, ok := operands[0].(*StringOperation); ok {
+		switch {
+		case op == LEN && (inner.Operator == TO_BYTES || inner.Operator == FROM_BYTES):
+			if res := foldString(inner.Operands, LEN); res != nil {
+				return res
+			}
+			return NewStringOperation(LEN, inner.Operands[0])
+		case op == TO_BYTES && inner.Operator == FROM_BYTES, op == FROM_BYTES && inner.Operator == TO_BYTES:
+			return inner.Operands[0]
+		}
+	}
+
+	if len(operands) == 2 {
+		if sub, ok := operands[1].(*StringConstant); ok && sub.Value == "" {
+			switch op {
+			case HAS_PREFIX, HAS_SUFFIX, CONTAINS:
+				return NewBoolConstant(true)
+			case INDEX:
+				return NewIntConstant(0)
+			}
+		}
+	}
+
+	str, ok := operands[0].(*StringConstant)
+	if !ok {
+		return nil
+	}
+
+	switch op {
+	case LEN:
+		return NewIntConstant(int64(len(str.Value)))
+	case AT:
+		if i, ok := operands[1].(*IntConstant); ok && i.Value >= 0 && i.Value < int64(len(str.Value)) {
+			return NewTypedIntConstant(int64(str.Value[i.Value]), ByteType)
+		}
+	case SLICE:
+		lo, loOk := operands[1].(*IntConstant)
+		hi, hiOk := operands[2].(*IntConstant)
+		if loOk && hiOk && 0 <= lo.Value && lo.Value <= hi.Value && hi.Value <= int64(len(str.Value)) {
+			return NewStringConstant(str.Value[lo.Value:hi.Value])
+		}
+	case HAS_PREFIX, HAS_SUFFIX, CONTAINS, INDEX:
+		sub, ok := operands[1].(*StringConstant)
+		if !ok {
+			return nil
+		}
+		switch op {
+		case HAS_PREFIX:
+			return NewBoolConstant(strings.HasPrefix(str.Value, sub.Value))
+		case HAS_SUFFIX:
+			return NewBoolConstant(strings.HasSuffix(str.Value, sub.Value))
+		case CONTAINS:
+			return NewBoolConstant(strings.Contains(str.Value, sub.Value))
+		default:
+			return NewIntConstant(int64(strings.Index(str.Value, sub.Value)))
+		}
+	}
+	return nil
+}
+
+// foldBinary вычисляет операцию над константами. Возвращает nil, если операнды
+// не константы или результат не определён (деление на ноль, отрицательный сдвиг).
+func foldBinary(left, right SymbolicExpression, op BinaryOperator) SymbolicExpression {
+	switch l := left.(type) {
+	case *IntConstant:
+		if r, ok := right.(*IntConstant); ok {
+			return foldInt(l, r, op)
+		}
+	case *FloatConstant:
+		if r, ok := right.(*FloatConstant); ok && l.Type() == r.Type() {
+			return foldFloat(l, r, op)
+		}
+	case *BoolConstant:
+		if r, ok := right.(*BoolConstant); ok {
+			switch op {
+			case EQ:
+				return NewBoolConstant(l.Value == r.Value)
+			case NE:
+				return NewBoolConstant(l.Value != r.Value)
+			}
+		}
+	case *StringConstant:
+		if r, ok := right.(*StringConstant); ok {
+			if op == ADD {
+				return NewStringConstant(l.Value + r.Value)
+			}
+			return compare(strings.Compare(l.Value, r.Value), op)
+		}
+	}
+	return nil
+}
+
+// foldInt вычисляет целочисленную операцию так же, как Go для типа левого операнда
+func foldInt(l, r *IntConstant, op BinaryOperator) SymbolicExpression {
+	tpe := l.Type()
+	a, b := l.Value, r.Value
+	// Значения беззнаковых типов хранятся с тем же битовым представлением
+	ua, ub := uint64(a), uint64(b)
+
+	switch op {
+	case ADD:
+		return NewTypedIntConstant(a+b, tpe)
+	case SUB:
+		return NewTypedIntConstant(a-b, tpe)
+	case MUL:
+		return NewTypedIntConstant(a*b, tpe)
+	case DIV, MOD:
+		if b == 0 {
+			return nil
+		}
+		switch {
+		case tpe.IsSigned() && op == DIV:
+			return NewTypedIntConstant(a/b, tpe)
+		case tpe.IsSigned():
+			return NewTypedIntConstant(a%b, tpe)
+		case op == DIV:
+			return NewTypedIntConstant(int64(ua/ub), tpe)
+		default:
+			return NewTypedIntConstant(int64(ua%ub), tpe)
+		}
+	case BAND:
+		return NewTypedIntConstant(a&b, tpe)
+	case BOR:
+		return NewTypedIntConstant(a|b, tpe)
+	case BXOR:
+		return NewTypedIntConstant(a^b, tpe)
+	case SHL, SHR:
+		if r.Type().IsSigned() && b < 0 {
+			return nil
+		}
+		switch {
+		case op == SHL:
+			return NewTypedIntConstant(a<<ub, tpe)
+		case tpe.IsSigned():
+			return NewTypedIntConstant(a>>ub, tpe)
+		default:
+			return NewTypedIntConstant(int64(ua>>ub), tpe)
+		}
+	}
+
+	if tpe.IsSigned() {
+		return compare(cmpOrdered(a, b), op)
+	}
+	return compare(cmpOrdered(ua, ub), op)
+}
+
+// foldFloat вычисляет операцию над числами с плавающей точкой по IEEE 754.
+// Для float32 округление результата float64 даёт тот же результат, что и вычисление во float32.
+func foldFloat(l, r *FloatConstant, op BinaryOperator) SymbolicExpression {
+	tpe := l.Type()
+	a, b := l.Value, r.Value
+
+	switch op {
+	case ADD:
+		return NewTypedFloatConstant(a+b, tpe)
+	case SUB:
+		return NewTypedFloatConstant(a-b, tpe)
+	case MUL:
+		return NewTypedFloatConstant(a*b, tpe)
+	case DIV:
+		return NewTypedFloatConstant(a/b, tpe)
+	case EQ:
+		return NewBoolConstant(a == b)
+	case NE:
+		return NewBoolConstant(a != b)
+	case LT:
+		return NewBoolConstant(a < b)
+	case LE:
+		return NewBoolConstant(a <= b)
+	case GT:
+		return NewBoolConstant(a > b)
+	case GE:
+		return NewBoolConstant(a >= b)
+	}
+	return nil
+}
+
+// compare превращает результат сравнения (-1, 0, 1) в значение оператора сравнения
+func compare(cmp int, op BinaryOperator) SymbolicExpression {
+	switch op {
+	case EQ:
+		return NewBoolConstant(cmp == 0)
+	case NE:
+		return NewBoolConstant(cmp != 0)
+	case LT:
+		return NewBoolConstant(cmp < 0)
+	case LE:
+		return NewBoolConstant(cmp <= 0)
+	case GT:
+		return NewBoolConstant(cmp > 0)
+	case GE:
+		return NewBoolConstant(cmp >= 0)
+	}
+	return nil
+}
+
+func cmpOrdered[T int64 | uint64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// inRange сообщает, представимо ли целое значение value в целочисленном типе
+func inRange(value float64, tpe ExpressionType) bool {
+	bits := float64(tpe.Bits())
+	if tpe.IsSigned() {
+		return value >= -math.Exp2(bits-1) && value < math.Exp2(bits-1)
+	}
+	return value >= 0 && value < math.Exp2(bits)
+}
+
+func isComparison(op BinaryOperator) bool {
+	switch op {
+	case EQ, NE, LT, LE, GT, GE:
+		return true
+	default:
+		return false
+	}
+}
+
+// isCommutative сообщает, можно ли переставить операнды операции. Конкатенация строк
+// не коммутативна, а сложение и умножение чисел с плавающей точкой коммутативны.
+func isCommutative(op BinaryOperator, tpe ExpressionType) bool {
+	switch op {
+	case ADD:
+		return tpe != StringType
+	case MUL, BAND, BOR, BXOR, EQ, NE:
+		return true
+	default:
+		return false
+	}
+}
+
+func isAssociative(op BinaryOperator) bool {
+	switch op {
+	case ADD, MUL, BAND, BOR, BXOR:
+		return true
+	default:
+		return false
+	}
+}
+
+// swapOperator возвращает оператор для переставленных операндов: a < b -> b > a
+func swapOperator(op BinaryOperator) BinaryOperator {
+	switch op {
+	case LT:
+		return GT
+	case LE:
+		return GE
+	case GT:
+		return LT
+	case GE:
+		return LE
+	default:
+		return op
+	}
+}
+
+// negateOperator возвращает оператор отрицания сравнения: a < b -> a >= b
+func negateOperator(op BinaryOperator) BinaryOperator {
+	switch op {
+	case EQ:
+		return NE
+	case NE:
+		return EQ
+	case LT:
+		return GE
+	case LE:
+		return GT
+	case GT:
+		return LE
+	case GE:
+		return LT
+	}
+	panic("not a comparison")
+}
+
+// less задаёт канонический порядок операндов: константы после остальных выражений,
+// внутри групп - по строковому представлению
+func less(a, b SymbolicExpression) bool {
+	if isConstant(a) != isConstant(b) {
+		return isConstant(b)
+	}
+	return a.String() < b.String()
+}
+
+func isConstant(expr SymbolicExpression) bool {
+	switch expr.(type) {
+	case *IntConstant, *BoolConstant, *FloatConstant, *StringConstant:
+		return true
+	default:
+		return false
+	}
+}
+
+func isIntValue(expr SymbolicExpression, value int64) bool {
+	c, ok := expr.(*IntConstant)
+	return ok && c.Value == c.Type().Wrap(value)
+}
+
+// same сообщает, совпадают ли выражения структурно
+func same(a, b SymbolicExpression) bool {
+	return a == b || a.Equal(b)
+}
@@ -0,0 +1,98 @@
+package symbolic
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimplify(t *testing.T) {
+	x := NewSymbolicVariable("x", IntType)
+	y := NewSymbolicVariable("y", IntType)
+	b := NewSymbolicVariable("b", BoolType)
+	f := NewSymbolicVariable("f", FloatType)
+	s := NewSymbolicVariable("s", StringType)
+	u8 := func(v int64) SymbolicExpression { return NewTypedIntConstant(v, Uint8Type) }
+	c := func(v int64) SymbolicExpression { return NewIntConstant(v) }
+	op := NewBinaryOperation
+	and := func(operands ...SymbolicExpression) SymbolicExpression { return NewLogicalOperation(operands, AND) }
+	not := func(operand SymbolicExpression) SymbolicExpression {
+		return NewLogicalOperation([]SymbolicExpression{operand}, NOT)
+	}
+
+	tests := []struct {
+		name     string
+		expr     SymbolicExpression
+		expected string
+	}{
+		{"int folding", op(op(c(2), c(3), MUL), c(1), ADD), "7"},
+		{"uint8 wraparound", op(u8(200), u8(100), ADD), "uint8(44)"},
+		{"signed division", op(c(-7), c(2), DIV), "-3"},
+		{"division by zero", op(c(1), c(0), DIV), "(1 / 0)"},
+		{"unsigned comparison", op(u8(-1), u8(1), GT), "true"},
+		{"float folding", op(NewFloatConstant(0.5), NewFloatConstant(0.25), ADD), "0.75"},
+		{"NaN comparison", op(NewFloatConstant(math.NaN()), NewFloatConstant(math.NaN()), EQ), "false"},
+		{"string folding", op(NewStringConstant("a"), NewStringConstant("b"), ADD), `"ab"`},
+		{"x + 0", op(x, c(0), ADD), "x"},
+		{"0 + x", op(c(0), x, ADD), "x"},
+		{"x * 1", op(x, c(1), MUL), "x"},
+		{"x * 0", op(x, c(0), MUL), "0"},
+		{"x - x", op(x, x, SUB), "0"},
+		{"double negation", op(op(x, c(-1), MUL), c(-1), MUL), "x"},
+		{"constant chain", op(op(x, c(1), ADD), c(2), ADD), "(x + 3)"},
+		{"float x + 0 kept", op(f, NewFloatConstant(0), ADD), "(f + 0)"},
+		{"float x == x kept", op(f, f, EQ), "(f == f)"},
+		{"x == x", op(x, x, EQ), "true"},
+		{"commutative order", op(y, x, ADD), "(x + y)"},
+		{"constant to the right", op(c(5), x, LT), "(x > 5)"},
+		{"comparison order", op(y, x, LE), "(x >= y)"},
+		{"string concat kept", op(NewStringConstant("a"), s, ADD), `("a" + s)`},
+		{"!!b", not(not(b)), "b"},
+		{"negated comparison", not(op(x, y, LT)), "(x >= y)"},
+		{"negated float comparison", not(op(f, NewFloatConstant(0), LT)), "!(f < 0)"},
+		{"flattening", and(and(NewBoolConstant(true), b), op(x, c(0), GT)), "(b && (x > 0))"},
+		{"duplicate conjuncts", and(b, and(b, op(x, c(0), GT))), "(b && (x > 0))"},
+		{"contradiction", and(b, not(b)), "false"},
+		{"absorbing or", NewLogicalOperation([]SymbolicExpression{b, NewBoolConstant(true)}, OR), "true"},
+		{"implication", NewLogicalOperation([]SymbolicExpression{b, NewBoolConstant(false)}, IMPLIES), "!b"},
+		{"ite", Ite(op(x, x, EQ), x, y), "x"},
+		{"conversion folding", NewConversion(c(0x1ff), ByteType), "uint8(255)"},
+		{"float truncation", NewConversion(NewFloatConstant(-2.5), IntType), "-2"},
+		{"widen and narrow", NewConversion(NewConversion(NewSymbolicVariable("n", Int8Type), IntType), Int8Type), "n"},
+		{"string len", NewStringOperation(LEN, NewStringOperation(TO_BYTES, NewStringConstant("abc"))), "3"},
+		{"string contains", NewStringOperation(CONTAINS, s, NewStringConstant("")), "true"},
+		{"bytes round-trip", NewStringOperation(FROM_BYTES, NewStringOperation(TO_BYTES, s)), "s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Simplify(tt.expr).String(); got != tt.expected {
+				t.Errorf("Simplify(%s) = %s, expected %s", tt.expr, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSimplifyArrays(t *testing.T) {
+	a := NewArrayVariable("a", ArraySort{})
+	i := NewSymbolicVariable("i", IntType)
+	store := NewArrayStore(NewArrayStore(a, NewIntConstant(1), NewIntConstant(10)), NewIntConstant(2), NewIntConstant(20))
+
+	tests := []struct {
+		name     string
+		expr     SymbolicExpression
+		expected string
+	}{
+		{"read after write", NewArraySelect(store, NewIntConstant(2)), "20"},
+		{"skip other index", NewArraySelect(store, NewIntConstant(1)), "10"},
+		{"untouched index", NewArraySelect(store, NewIntConstant(3)), "a[3]"},
+		{"symbolic index", NewArraySelect(store, i), "a[1 := 10][2 := 20][i]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Simplify(tt.expr).String(); got != tt.expected {
+				t.Errorf("Simplify(%s) = %s, expected %s", tt.expr, got, tt.expected)
+			}
+		})
+	}
+}
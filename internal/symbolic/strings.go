@@ -0,0 +1,190 @@
+package symbolic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StringConstant представляет строковую константу
+type StringConstant struct {
+	Value string
+}
+
+// NewStringConstant создаёт новую строковую константу
+func NewStringConstant(value string) *StringConstant {
+	return &StringConstant{Value: value}
+}
+
+// Type возвращает тип константы
+func (sc *StringConstant) Type() ExpressionType {
+	return StringType
+}
+
+// String возвращает строковое представление константы в синтаксисе Go
+func (sc *StringConstant) String() string {
+	return strconv.Quote(sc.Value)
+}
+
+// Accept реализует Visitor pattern
+func (sc *StringConstant) Accept(visitor Visitor) interface{} {
+	return visitor.VisitStringConstant(sc)
+}
+
+// StringOperator - операция над строками и срезами байт.
+// Конкатенация и сравнения выражаются через BinaryOperation (ADD, EQ, LT, ...).
+type StringOperator int
+
+const (
+	// LEN - длина строки или среза байт: len(s)
+	LEN StringOperator = iota
+	// AT - байт по индексу: s[i]
+	AT
+	// SLICE - подстрока или подсрез: s[lo:hi]
+	SLICE
+	// HAS_PREFIX - strings.HasPrefix(s, prefix)
+	HAS_PREFIX
+	// HAS_SUFFIX - strings.HasSuffix(s, suffix)
+	HAS_SUFFIX
+	// CONTAINS - strings.Contains(s, substr)
+	CONTAINS
+	// INDEX - strings.Index(s, substr), -1 если подстроки нет
+	INDEX
+	// TO_BYTES - преобразование []byte(s)
+	TO_BYTES
+	// FROM_BYTES - преобразование string(b)
+	FROM_BYTES
+)
+
+// String возвращает имя операции, используемое в строковом представлении выражений
+func (op StringOperator) String() string {
+	switch op {
+	case LEN:
+		return "len"
+	case AT:
+		return "at"
+	case SLICE:
+		return "slice"
+	case HAS_PREFIX:
+		return "hasPrefix"
+	case HAS_SUFFIX:
+		return "hasSuffix"
+	case CONTAINS:
+		return "contains"
+	case INDEX:
+		return "index"
+	case TO_BYTES:
+		return "bytes"
+	case FROM_BYTES:
+		return "string"
+	default:
+		return "unknown"
+	}
+}
+
+// StringOperation представляет операцию над строками и срезами байт
+type StringOperation struct {
+	Operands []SymbolicExpression
+	Operator StringOperator
+}
+
+// NewStringOperation создаёт операцию над строками, проверяя число и типы операндов:
+//   - LEN(s), TO_BYTES(s), FROM_BYTES(b);
+//   - AT(s, i), SLICE(s, lo, hi) с индексами типа int;
+//   - HAS_PREFIX, HAS_SUFFIX, CONTAINS, INDEX над двумя строками.
+func NewStringOperation(op StringOperator, operands ...SymbolicExpression) *StringOperation {
+	res, err := NewStringOperationChecked(op, operands...)
+	if err != nil {
+		panic(err.Error())
+	}
+	return res
+}
+
+// NewStringOperationChecked создаёт операцию над строками,
+// возвращая ошибку вместо паники при неверном числе или типе операндов
+func NewStringOperationChecked(op StringOperator, operands ...SymbolicExpression) (*StringOperation, error) {
+	if err := checkStringOperation(op, operands); err != nil {
+		return nil, err
+	}
+	return &StringOperation{
+		Operands: operands,
+		Operator: op,
+	}, nil
+}
+
+func checkStringOperation(op StringOperator, operands []SymbolicExpression) error {
+	for _, operand := range operands {
+		if operand == nil {
+			return ErrMissingOperand
+		}
+	}
+
+	var expected []ExpressionType
+	switch op {
+	case LEN:
+		expected = []ExpressionType{sequenceType(operands)}
+	case AT:
+		expected = []ExpressionType{sequenceType(operands), IntType}
+	case SLICE:
+		expected = []ExpressionType{sequenceType(operands), IntType, IntType}
+	case HAS_PREFIX, HAS_SUFFIX, CONTAINS, INDEX:
+		expected = []ExpressionType{StringType, StringType}
+	case TO_BYTES:
+		expected = []ExpressionType{StringType}
+	case FROM_BYTES:
+		expected = []ExpressionType{BytesType}
+	default:
+		return ErrUnknownOperator
+	}
+
+	if len(operands) != len(expected) {
+		return ErrArgumentCount
+	}
+	for i, operand := range operands {
+		if operand.Type() != expected[i] {
+			return ErrIncorrectType
+		}
+	}
+	return nil
+}
+
+// sequenceType возвращает тип первого операнда, если это строка или срез байт
+func sequenceType(operands []SymbolicExpression) ExpressionType {
+	if len(operands) > 0 && operands[0].Type().IsSequence() {
+		return operands[0].Type()
+	}
+	return StringType
+}
+
+// Type возвращает тип результата операции
+func (so *StringOperation) Type() ExpressionType {
+	switch so.Operator {
+	case LEN, INDEX:
+		return IntType
+	case AT:
+		return ByteType
+	case SLICE:
+		return so.Operands[0].Type()
+	case HAS_PREFIX, HAS_SUFFIX, CONTAINS:
+		return BoolType
+	case TO_BYTES:
+		return BytesType
+	case FROM_BYTES:
+		return StringType
+	}
+	panic("not implemented")
+}
+
+// String возвращает строковое представление операции в виде вызова: "len(s)", "slice(s, 1, 3)"
+func (so *StringOperation) String() string {
+	operands := make([]string, len(so.Operands))
+	for i, operand := range so.Operands {
+		operands[i] = operand.String()
+	}
+	return fmt.Sprintf("%s(%s)", so.Operator, strings.Join(operands, ", "))
+}
+
+// Accept реализует Visitor pattern
+func (so *StringOperation) Accept(visitor Visitor) interface{} {
+	return visitor.VisitStringOperation(so)
+}
@@ -11,9 +11,36 @@ const (
 	ArrayType
 	ObjectType
 	ReferenceType
+
+	// Целочисленные типы фиксированной ширины. IntType соответствует int
+	// и, как на 64-битных платформах, имеет ширину 64 бита.
+	Int8Type
+	Int16Type
+	Int32Type
+	Int64Type
+	UintType
+	Uint8Type
+	Uint16Type
+	Uint32Type
+	Uint64Type
+	UintptrType
+
+	// Float32Type соответствует float32, FloatType - float64
+	Float32Type
+
+	// StringType соответствует string, BytesType - []byte.
+	// Оба типа - последовательности байт известной длины.
+	StringType
+	BytesType
 	// Добавьте другие типы по необходимости
 )
 
+// Синонимы типов Go
+const (
+	ByteType = Uint8Type
+	RuneType = Int32Type
+)
+
 // String возвращает строковое представление типа
 func (et ExpressionType) String() string {
 	switch et {
@@ -29,7 +56,108 @@ func (et ExpressionType) String() string {
 		return "object"
 	case ReferenceType:
 		return "ref"
+	case Int8Type:
+		return "int8"
+	case Int16Type:
+		return "int16"
+	case Int32Type:
+		return "int32"
+	case Int64Type:
+		return "int64"
+	case UintType:
+		return "uint"
+	case Uint8Type:
+		return "uint8"
+	case Uint16Type:
+		return "uint16"
+	case Uint32Type:
+		return "uint32"
+	case Uint64Type:
+		return "uint64"
+	case UintptrType:
+		return "uintptr"
+	case Float32Type:
+		return "float32"
+	case StringType:
+		return "string"
+	case BytesType:
+		return "[]byte"
 	default:
 		return "unknown"
 	}
 }
+
+// IsInteger сообщает, является ли тип целочисленным
+func (et ExpressionType) IsInteger() bool {
+	switch et {
+	case IntType, Int8Type, Int16Type, Int32Type, Int64Type,
+		UintType, Uint8Type, Uint16Type, Uint32Type, Uint64Type, UintptrType:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsFloat сообщает, является ли тип типом с плавающей точкой
+func (et ExpressionType) IsFloat() bool {
+	return et == FloatType || et == Float32Type
+}
+
+// IsSequence сообщает, является ли тип последовательностью байт: string или []byte
+func (et ExpressionType) IsSequence() bool {
+	return et == StringType || et == BytesType
+}
+
+// IsSigned сообщает, является ли целочисленный тип знаковым
+func (et ExpressionType) IsSigned() bool {
+	switch et {
+	case IntType, Int8Type, Int16Type, Int32Type, Int64Type:
+		return true
+	default:
+		return false
+	}
+}
+
+// Bits возвращает ширину целочисленного типа в битах, для остальных типов - 0
+func (et ExpressionType) Bits() int {
+	switch et {
+	case Int8Type, Uint8Type:
+		return 8
+	case Int16Type, Uint16Type:
+		return 16
+	case Int32Type, Uint32Type:
+		return 32
+	case IntType, Int64Type, UintType, Uint64Type, UintptrType:
+		return 64
+	default:
+		return 0
+	}
+}
+
+// Wrap приводит значение к ширине целочисленного типа так же, как переполнение в Go:
+// лишние старшие биты отбрасываются, а результат расширяется знаком или нулями.
+// Беззнаковые 64-битные значения хранятся в int64 с тем же битовым представлением.
+func (et ExpressionType) Wrap(value int64) int64 {
+	bits := et.Bits()
+	if bits == 0 || bits == 64 {
+		return value
+	}
+
+	shift := 64 - bits
+	if et.IsSigned() {
+		return value << shift >> shift
+	}
+	return int64(uint64(value) << shift >> shift)
+}
+
+// ArraySort описывает типы индексов и элементов символьного массива.
+// Нулевое значение соответствует массиву int -> int.
+type ArraySort struct {
+	Index   ExpressionType
+	Element ExpressionType
+}
+
+// String возвращает строковое представление сорта массива, например "[int]uint8"
+func (as ArraySort) String() string {
+	return "[" + as.Index.String() + "]" + as.Element.String()
+}
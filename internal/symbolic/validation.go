@@ -0,0 +1,246 @@
+package symbolic
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Ошибки типизации выражений. Их возвращают конструкторы New...Checked
+// и Validate (в поле Err ошибки ValidationError), а конструкторы New... паникуют
+// с текстом этих ошибок.
+var (
+	ErrIncompatibleTypes = errors.New("incompatible types")
+	ErrIncorrectType     = errors.New("incorrect type")
+	ErrArgumentCount     = errors.New("incorrect number of arguments")
+	ErrUnknownOperator   = errors.New("unknown operator")
+	ErrMissingOperand    = errors.New("missing operand")
+	ErrValueOutOfRange   = errors.New("value out of range")
+)
+
+// ValidationError описывает некорректный узел выражения
+type ValidationError struct {
+	// Expr - некорректное подвыражение
+	Expr SymbolicExpression
+	// Path - путь к подвыражению от корня по именам полей,
+	// например "Operands[1].Left"; пуст, если некорректен сам корень
+	Path string
+	// Err - причина, одна из ошибок Err...
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%s: %v", describe(e.Expr), e.Err)
+	}
+	return fmt.Sprintf("%s %s: %v", e.Path, describe(e.Expr), e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// describe возвращает строковое представление узла. Некорректный узел
+// может не уметь себя напечатать, тогда возвращается имя его типа.
+func describe(expr SymbolicExpression) (res string) {
+	defer func() {
+		if recover() != nil {
+			res = fmt.Sprintf("%T", expr)
+		}
+	}()
+	return expr.String()
+}
+
+// Validate проверяет типизацию всего дерева выражения по тем же правилам,
+// что и конструкторы, и возвращает *ValidationError для первого некорректного
+// подвыражения при обходе снизу вверх. Проверяются также узлы, собранные
+// без конструкторов: отсутствующие операнды, неизвестные операторы и типы,
+// константы вне диапазона своего типа. Выражение, прошедшее проверку,
+// можно безопасно печатать, сравнивать и упрощать.
+func Validate(expr SymbolicExpression) error {
+	v := &validator{}
+	if err := v.child("", expr); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validator обходит выражение, накапливая путь к текущему узлу
+type validator struct {
+	path []string
+}
+
+// child проверяет подвыражение, доступное из текущего узла по полю field
+func (v *validator) child(field string, expr SymbolicExpression) *ValidationError {
+	if field != "" {
+		v.path = append(v.path, field)
+		defer func() { v.path = v.path[:len(v.path)-1] }()
+	}
+
+	if expr == nil {
+		return v.fail(nil, ErrMissingOperand)
+	}
+	// Ссылка разыменовывается при Accept, а её значение проверяется при записи в память
+	if _, ok := expr.(*Ref); ok {
+		return nil
+	}
+	if err := expr.Accept(v); err != nil {
+		return err.(*ValidationError)
+	}
+	return nil
+}
+
+func (v *validator) children(field string, exprs []SymbolicExpression) *ValidationError {
+	for i, expr := range exprs {
+		if err := v.child(fmt.Sprintf("%s[%d]", field, i), expr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fail создаёт ошибку для узла по текущему пути
+func (v *validator) fail(expr SymbolicExpression, err error) *ValidationError {
+	return &ValidationError{Expr: expr, Path: strings.Join(v.path, "."), Err: err}
+}
+
+// check возвращает результат проверки узла в виде, пригодном для Accept
+func (v *validator) check(expr SymbolicExpression, err error) interface{} {
+	if err != nil {
+		return v.fail(expr, err)
+	}
+	return nil
+}
+
+// isKnownType сообщает, объявлен ли тип в ExpressionType
+func isKnownType(tpe ExpressionType) bool {
+	return tpe >= IntType && tpe <= BytesType
+}
+
+// isScalarType сообщает, может ли тип быть индексом или элементом массива
+func isScalarType(tpe ExpressionType) bool {
+	return isKnownType(tpe) && tpe != ArrayType && tpe != ObjectType && tpe != ReferenceType
+}
+
+func (v *validator) VisitVariable(expr *SymbolicVariable) interface{} {
+	if !isKnownType(expr.ExprType) {
+		return v.fail(expr, ErrIncorrectType)
+	}
+	if expr.ExprType == ArrayType && (!isScalarType(expr.Sort.Index) || !isScalarType(expr.Sort.Element)) {
+		return v.fail(expr, ErrIncorrectType)
+	}
+	return nil
+}
+
+func (v *validator) VisitIntConstant(expr *IntConstant) interface{} {
+	if !expr.ExprType.IsInteger() {
+		return v.fail(expr, ErrIncorrectType)
+	}
+	if expr.ExprType.Wrap(expr.Value) != expr.Value {
+		return v.fail(expr, ErrValueOutOfRange)
+	}
+	return nil
+}
+
+func (v *validator) VisitBoolConstant(*BoolConstant) interface{} {
+	return nil
+}
+
+func (v *validator) VisitFloatConstant(expr *FloatConstant) interface{} {
+	if !expr.ExprType.IsFloat() {
+		return v.fail(expr, ErrIncorrectType)
+	}
+	if expr.ExprType == Float32Type && !math.IsNaN(expr.Value) && float64(float32(expr.Value)) != expr.Value {
+		return v.fail(expr, ErrValueOutOfRange)
+	}
+	return nil
+}
+
+func (v *validator) VisitStringConstant(*StringConstant) interface{} {
+	return nil
+}
+
+func (v *validator) VisitBinaryOperation(expr *BinaryOperation) interface{} {
+	if err := v.child("Left", expr.Left); err != nil {
+		return err
+	}
+	if err := v.child("Right", expr.Right); err != nil {
+		return err
+	}
+	return v.check(expr, checkBinaryOperation(expr.Left, expr.Right, expr.Operator))
+}
+
+func (v *validator) VisitUnaryOperation(expr *UnaryOperation) interface{} {
+	if err := v.child("Left", expr.Left); err != nil {
+		return err
+	}
+	return v.check(expr, checkUnaryOperation(expr.Left, expr.Operator))
+}
+
+func (v *validator) VisitLogicalOperation(expr *LogicalOperation) interface{} {
+	if err := v.children("Operands", expr.Operands); err != nil {
+		return err
+	}
+	return v.check(expr, checkLogicalOperation(expr.Operands, expr.Operator))
+}
+
+func (v *validator) VisitConditionalExpression(expr *ConditionalExpression) interface{} {
+	if err := v.child("Condition", expr.Condition); err != nil {
+		return err
+	}
+	if err := v.child("Then", expr.Then); err != nil {
+		return err
+	}
+	if err := v.child("Else", expr.Else); err != nil {
+		return err
+	}
+	return v.check(expr, checkConditionalExpression(expr.Condition, expr.Then, expr.Else))
+}
+
+func (v *validator) VisitArraySelect(expr *ArraySelect) interface{} {
+	if err := v.child("Array", expr.Array); err != nil {
+		return err
+	}
+	if err := v.child("Index", expr.Index); err != nil {
+		return err
+	}
+	return v.check(expr, checkArraySelect(expr.Array, expr.Index))
+}
+
+func (v *validator) VisitArrayStore(expr *ArrayStore) interface{} {
+	if err := v.child("Array", expr.Array); err != nil {
+		return err
+	}
+	if err := v.child("Index", expr.Index); err != nil {
+		return err
+	}
+	if err := v.child("Value", expr.Value); err != nil {
+		return err
+	}
+	return v.check(expr, checkArrayStore(expr.Array, expr.Index, expr.Value))
+}
+
+func (v *validator) VisitFunctionApplication(expr *FunctionApplication) interface{} {
+	if err := v.children("Args", expr.Args); err != nil {
+		return err
+	}
+	return v.check(expr, checkFunctionApplication(expr.Function, expr.Args))
+}
+
+func (v *validator) VisitStringOperation(expr *StringOperation) interface{} {
+	if err := v.children("Operands", expr.Operands); err != nil {
+		return err
+	}
+	return v.check(expr, checkStringOperation(expr.Operator, expr.Operands))
+}
+
+func (v *validator) VisitConversion(expr *Conversion) interface{} {
+	if err := v.child("Operand", expr.Operand); err != nil {
+		return err
+	}
+	if !isKnownType(expr.Target) {
+		return v.fail(expr, ErrIncorrectType)
+	}
+	return v.check(expr, checkConversion(expr.Operand, expr.Target))
+}
@@ -0,0 +1,134 @@
+package symbolic
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckedConstructors(t *testing.T) {
+	x := NewSymbolicVariable("x", IntType)
+	b := NewSymbolicVariable("b", BoolType)
+	s := NewSymbolicVariable("s", StringType)
+	f := NewSymbolicVariable("f", FloatType)
+
+	tests := []struct {
+		name     string
+		build    func() (SymbolicExpression, error)
+		expected error
+	}{
+		{"binary", func() (SymbolicExpression, error) { return NewBinaryOperationChecked(x, b, ADD) }, ErrIncompatibleTypes},
+		{"binary operator", func() (SymbolicExpression, error) { return NewBinaryOperationChecked(x, x, BinaryOperator(100)) }, ErrUnknownOperator},
+		{"binary nil", func() (SymbolicExpression, error) { return NewBinaryOperationChecked(x, nil, ADD) }, ErrMissingOperand},
+		{"logical count", func() (SymbolicExpression, error) {
+			return NewLogicalOperationChecked([]SymbolicExpression{b}, AND)
+		}, ErrArgumentCount},
+		{"logical type", func() (SymbolicExpression, error) {
+			return NewLogicalOperationChecked([]SymbolicExpression{b, x}, OR)
+		}, ErrIncorrectType},
+		{"unary", func() (SymbolicExpression, error) { return NewUnaryOperationChecked(b, BNOT) }, ErrIncompatibleTypes},
+		{"conditional", func() (SymbolicExpression, error) { return NewConditionalExpressionChecked(b, x, s) }, ErrIncompatibleTypes},
+		{"select", func() (SymbolicExpression, error) { return NewArraySelectChecked(x, x) }, ErrIncompatibleTypes},
+		{"string operation", func() (SymbolicExpression, error) { return NewStringOperationChecked(LEN, x) }, ErrIncorrectType},
+		{"conversion", func() (SymbolicExpression, error) { return NewConversionChecked(s, IntType) }, ErrIncompatibleTypes},
+		{"application", func() (SymbolicExpression, error) {
+			return NewFunctionApplicationChecked(&FunctionDeclaration{Name: "f", Params: []ExpressionType{IntType}}, b)
+		}, ErrIncompatibleTypes},
+		{"typed constant", func() (SymbolicExpression, error) { return NewTypedIntConstantChecked(1, BoolType) }, ErrIncorrectType},
+		{"valid", func() (SymbolicExpression, error) { return NewBinaryOperationChecked(x, x, ADD) }, nil},
+		{"bool addition", func() (SymbolicExpression, error) {
+			return NewBinaryOperationChecked(NewBoolConstant(true), NewBoolConstant(false), ADD)
+		}, ErrIncompatibleTypes},
+		{"bool ordering", func() (SymbolicExpression, error) {
+			return NewBinaryOperationChecked(NewBoolConstant(true), NewBoolConstant(false), LT)
+		}, ErrIncompatibleTypes},
+		{"bool bitwise", func() (SymbolicExpression, error) { return NewBinaryOperationChecked(b, b, BAND) }, ErrIncompatibleTypes},
+		{"string product", func() (SymbolicExpression, error) {
+			return NewBinaryOperationChecked(NewStringConstant("a"), NewStringConstant("b"), MUL)
+		}, ErrIncompatibleTypes},
+		{"string shift", func() (SymbolicExpression, error) { return NewBinaryOperationChecked(s, s, SHL) }, ErrIncompatibleTypes},
+		{"float remainder", func() (SymbolicExpression, error) {
+			return NewBinaryOperationChecked(NewFloatConstant(1.5), NewFloatConstant(2), MOD)
+		}, ErrIncompatibleTypes},
+		{"float shift", func() (SymbolicExpression, error) { return NewBinaryOperationChecked(f, NewIntConstant(1), SHL) }, ErrIncompatibleTypes},
+		{"mixed bitwise", func() (SymbolicExpression, error) { return NewBinaryOperationChecked(x, f, BXOR) }, ErrIncompatibleTypes},
+		{"valid bool equality", func() (SymbolicExpression, error) { return NewBinaryOperationChecked(b, b, NE) }, nil},
+		{"valid string ordering", func() (SymbolicExpression, error) { return NewBinaryOperationChecked(s, s, LE) }, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.build()
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("expected error %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestConstructorsPanicWithErrorText(t *testing.T) {
+	defer func() {
+		if r := recover(); r != "incompatible types" {
+			t.Errorf("expected panic %q, got %v", "incompatible types", r)
+		}
+	}()
+	NewBinaryOperation(NewIntConstant(1), NewBoolConstant(true), ADD)
+}
+
+func TestValidate(t *testing.T) {
+	x := NewSymbolicVariable("x", IntType)
+	b := NewSymbolicVariable("b", BoolType)
+	valid := NewBinaryOperation(x, NewIntConstant(1), ADD)
+
+	tests := []struct {
+		name     string
+		expr     SymbolicExpression
+		path     string
+		expected error
+	}{
+		{"valid", NewLogicalOperation([]SymbolicExpression{b, NewBinaryOperation(valid, x, LT)}, AND), "", nil},
+		{"root", &BinaryOperation{Left: x, Right: b, Operator: ADD}, "", ErrIncompatibleTypes},
+		{"nested", &LogicalOperation{Operator: AND, Operands: []SymbolicExpression{
+			b,
+			&BinaryOperation{Left: &BinaryOperation{Left: x, Right: b, Operator: MUL}, Right: x, Operator: LT},
+		}}, "Operands[1].Left", ErrIncompatibleTypes},
+		{"missing operand", &ConditionalExpression{Condition: b, Then: x}, "Else", ErrMissingOperand},
+		{"unknown operator", &UnaryOperation{Left: x, Operator: UnaryOperator(7)}, "", ErrUnknownOperator},
+		{"constant out of range", &BinaryOperation{
+			Left: NewTypedIntConstant(1, Uint8Type), Right: &IntConstant{Value: 300, ExprType: Uint8Type}, Operator: ADD,
+		}, "Right", ErrValueOutOfRange},
+		{"unknown type", &Conversion{Operand: x, Target: ExpressionType(100)}, "", ErrIncorrectType},
+		{"application", &FunctionApplication{
+			Function: NewFunctionDeclaration("f", IntType, IntType, IntType),
+			Args:     []SymbolicExpression{x},
+		}, "", ErrArgumentCount},
+		{"bool arithmetic", &BinaryOperation{Left: NewBoolConstant(true), Right: b, Operator: ADD}, "", ErrIncompatibleTypes},
+		{"bool ordering", &LogicalOperation{Operator: NOT, Operands: []SymbolicExpression{
+			&BinaryOperation{Left: NewBoolConstant(true), Right: NewBoolConstant(false), Operator: LT},
+		}}, "Operands[0]", ErrIncompatibleTypes},
+		{"string product", &BinaryOperation{Left: NewStringConstant("a"), Right: NewStringConstant("b"), Operator: MUL}, "", ErrIncompatibleTypes},
+		{"float remainder", &BinaryOperation{Left: NewFloatConstant(1.5), Right: NewFloatConstant(2), Operator: MOD}, "", ErrIncompatibleTypes},
+		{"float shift", &BinaryOperation{
+			Left: NewSymbolicVariable("f", FloatType), Right: NewIntConstant(1), Operator: SHL,
+		}, "", ErrIncompatibleTypes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.expr)
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("expected error %v, got %v", tt.expected, err)
+			}
+			if err == nil {
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected *ValidationError, got %T", err)
+			}
+			if validationErr.Path != tt.path {
+				t.Errorf("expected path %q, got %q (%v)", tt.path, validationErr.Path, err)
+			}
+		})
+	}
+}
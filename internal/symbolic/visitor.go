@@ -9,5 +9,12 @@ type Visitor interface {
 	VisitBinaryOperation(expr *BinaryOperation) interface{}
 	VisitUnaryOperation(expr *UnaryOperation) interface{}
 	VisitLogicalOperation(expr *LogicalOperation) interface{}
+	VisitConditionalExpression(expr *ConditionalExpression) interface{}
+	VisitArraySelect(expr *ArraySelect) interface{}
+	VisitArrayStore(expr *ArrayStore) interface{}
+	VisitFunctionApplication(expr *FunctionApplication) interface{}
+	VisitStringConstant(expr *StringConstant) interface{}
+	VisitStringOperation(expr *StringOperation) interface{}
+	VisitConversion(expr *Conversion) interface{}
 	// TODO: Добавьте методы для других типов выражений по мере необходимости
 }
@@ -0,0 +1,102 @@
+package translator
+
+import (
+	"errors"
+	"fmt"
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/pkg/z3wrapper"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+// Verdict - результат проверки выполнимости условий
+type Verdict int
+
+const (
+	// Unknown - решатель не смог определить выполнимость
+	Unknown Verdict = iota
+	// Sat - условия выполнимы
+	Sat
+	// Unsat - условия невыполнимы
+	Unsat
+)
+
+// String возвращает ответ решателя: "sat", "unsat" или "unknown"
+func (v Verdict) String() string {
+	switch v {
+	case Sat:
+		return "sat"
+	case Unsat:
+		return "unsat"
+	}
+	return "unknown"
+}
+
+// SequenceTimeout - ограничение времени проверки в теории строк в миллисекундах
+const SequenceTimeout = 10000
+
+// Check проверяет выполнимость конъюнкции условий и возвращает модель, если её нашёл Z3Translator.
+//
+// Модель в пределах StringBound - настоящее решение, а "unsat" доказан только для строк
+// не длиннее границы. Поэтому невыполнимость условий со строковыми переменными
+// перепроверяется без границы в теории строк Z3 (SMTLib2Translator, SetStringBound(0)).
+// Если и там ответа нет, результат - Unknown, а не Unsat; для Sat из теории строк модель не возвращается.
+func (zt *Z3Translator) Check(conditions ...symbolic.SymbolicExpression) (Verdict, *z3.Model, error) {
+	solver := z3.NewSolver(zt.ctx)
+	withStrings := false
+	for _, cond := range conditions {
+		translated, err := zt.TranslateExpression(cond)
+		if err != nil {
+			return Unknown, nil, err
+		}
+		b, ok := translated.(z3.Bool)
+		if !ok {
+			return Unknown, nil, NewTranslationError(fmt.Sprintf("condition of type %s", cond.Type()), cond)
+		}
+		solver.Assert(b)
+
+		for _, v := range symbolic.FreeVariables(cond) {
+			withStrings = withStrings || v.ExprType.IsSequence()
+		}
+	}
+	for _, assumption := range zt.Assumptions() {
+		solver.Assert(assumption)
+	}
+
+	sat, err := solver.Check()
+	var unknown *z3.ErrSatUnknown
+	switch {
+	case errors.As(err, &unknown):
+		return Unknown, nil, nil
+	case err != nil:
+		return Unknown, nil, err
+	case sat:
+		return Sat, solver.Model(), nil
+	case !withStrings:
+		return Unsat, nil, nil
+	}
+	verdict, err := checkSequences(conditions)
+	return verdict, nil, err
+}
+
+// checkSequences проверяет условия в теории строк без ограничения длины
+func checkSequences(conditions []symbolic.SymbolicExpression) (Verdict, error) {
+	st := NewSMTLib2Translator()
+	st.SetStringBound(0)
+	script, err := st.Script(conditions...)
+	if err != nil {
+		return Unknown, err
+	}
+
+	output, err := z3wrapper.RunSMTLib2(fmt.Sprintf("(set-option :timeout %d)\n%s", SequenceTimeout, script))
+	if err != nil {
+		return Unknown, err
+	}
+	switch output {
+	case "sat":
+		return Sat, nil
+	case "unsat":
+		return Unsat, nil
+	}
+	return Unknown, nil
+}
@@ -0,0 +1,54 @@
+package translator
+
+import (
+	"testing"
+
+	"symbolic-execution-course/internal/symbolic"
+)
+
+func TestCheck(t *testing.T) {
+	op := symbolic.NewBinaryOperation
+	num := symbolic.NewIntConstant
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	s := symbolic.NewSymbolicVariable("s", symbolic.StringType)
+	length := symbolic.NewStringOperation(symbolic.LEN, s)
+
+	tests := []struct {
+		name       string
+		bound      int
+		conditions []symbolic.SymbolicExpression
+		expected   Verdict
+		model      bool
+	}{
+		{"sat", DefaultStringBound, []symbolic.SymbolicExpression{op(x, num(1), symbolic.GT)}, Sat, true},
+		{"unsat", DefaultStringBound, []symbolic.SymbolicExpression{op(x, num(1), symbolic.GT), op(x, num(0), symbolic.LT)}, Unsat, false},
+		{"string within bound", DefaultStringBound, []symbolic.SymbolicExpression{op(length, num(3), symbolic.EQ)}, Sat, true},
+		// Невыполнимость в пределах границы перепроверяется в теории строк без ограничения длины
+		{"string beyond bound", 2, []symbolic.SymbolicExpression{op(length, num(3), symbolic.EQ)}, Sat, false},
+		{"string unsat", 2, []symbolic.SymbolicExpression{
+			op(length, num(3), symbolic.EQ),
+			op(s, symbolic.NewStringConstant("ab"), symbolic.EQ),
+		}, Unsat, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zt := NewZ3Translator()
+			zt.SetStringBound(tt.bound)
+			verdict, model, err := zt.Check(tt.conditions...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if verdict != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, verdict)
+			}
+			if (model != nil) != tt.model {
+				t.Errorf("expected model: %t, got %v", tt.model, model)
+			}
+		})
+	}
+
+	if _, _, err := NewZ3Translator().Check(x); err == nil {
+		t.Error("expected an error for a non-boolean condition")
+	}
+}
@@ -0,0 +1,611 @@
+package translator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// SMTLib2Translator транслирует символьные выражения в текст SMT-LIB2.
+// Целые числа, как и в Z3Translator, - битовые векторы ширины своего типа,
+// числа с плавающей точкой - FloatingPoint 8 24 и 11 53, массивы - Array.
+//
+// Строки кодируются иначе, чем в Z3Translator: вместо последовательности байт
+// ограниченной ёмкости используется сорт String теории строк, а каждый байт - символ
+// с тем же кодом, поэтому длины и сравнения совпадают с побайтовой семантикой Go.
+// Длина строковых переменных ограничена StringBound (см. Assumptions), так что множества
+// решений обеих кодировок совпадают. SetStringBound(0) снимает границу, которую
+// Z3Translator снять не может, но тогда решатели перебирают длины,
+// не представимые в int, и могут не завершиться.
+// Переход между длинами и индексами (Int) и битовыми векторами использует функции
+// bv2nat и int2bv, которых нет в стандарте SMT-LIB 2.6: скрипты со строками
+// рассчитаны на Z3 и решатели, поддерживающие это расширение.
+//
+// Результат трансляции выражения - терм SMT-LIB2 (string). Объявления переменных
+// и функций накапливаются до Reset в порядке первого вхождения, поэтому запросы
+// для одного и того же пути совпадают текстуально и удобны для сравнения.
+type SMTLib2Translator struct {
+	declared     map[string]string // Объявления по имени символа
+	declarations []string
+
+	stringBound int      // Максимальная длина строковых переменных
+	assumptions []string // Ограничения на строковые переменные
+}
+
+// NewSMTLib2Translator создаёт новый экземпляр SMT-LIB2 транслятора
+func NewSMTLib2Translator() *SMTLib2Translator {
+	return &SMTLib2Translator{
+		declared:    make(map[string]string),
+		stringBound: DefaultStringBound,
+	}
+}
+
+// GetContext возвращает nil: SMT-LIB2 транслятору не нужен внешний контекст
+func (st *SMTLib2Translator) GetContext() interface{} {
+	return nil
+}
+
+// Reset сбрасывает накопленные объявления и ограничения
+func (st *SMTLib2Translator) Reset() {
+	st.declared = make(map[string]string)
+	st.declarations = nil
+	st.assumptions = nil
+}
+
+// SetStringBound задаёт максимальную длину строковых переменных.
+// Граница 0 снимает ограничение: длины не ограничены, как в Go, но решатель может не завершиться.
+func (st *SMTLib2Translator) SetStringBound(bound int) {
+	st.stringBound = bound
+}
+
+// TranslateExpression транслирует выражение в терм SMT-LIB2.
+// Выражение предварительно проверяется symbolic.Validate.
+func (st *SMTLib2Translator) TranslateExpression(expr symbolic.SymbolicExpression) (interface{}, error) {
+	return st.term(expr)
+}
+
+// Declarations возвращает команды declare-fun для всех переменных и функций,
+// встретившихся в транслированных выражениях
+func (st *SMTLib2Translator) Declarations() []string {
+	return st.declarations
+}
+
+// Assumptions возвращает термы ограничений на строковые переменные: длина не больше
+// StringBound и коды символов не больше 255. Как и у Z3Translator, их нужно
+// утверждать отдельно от проверяемой формулы.
+func (st *SMTLib2Translator) Assumptions() []string {
+	return st.assumptions
+}
+
+// Script возвращает законченный запрос SMT-LIB2: объявления, ограничения
+// строковых переменных, assert для каждого условия и check-sat. Конъюнкции верхнего уровня разбиваются на отдельные assert,
+// так что условие пути записывается по одному ограничению на строку.
+func (st *SMTLib2Translator) Script(conditions ...symbolic.SymbolicExpression) (string, error) {
+	var asserts []string
+	for _, cond := range conditions {
+		if err := symbolic.Validate(cond); err != nil {
+			return "", NewTranslationError(err.Error(), cond)
+		}
+		if cond.Type() != symbolic.BoolType {
+			return "", NewTranslationError("assertion must be boolean", cond)
+		}
+		for _, conjunct := range conjuncts(cond) {
+			term, err := st.term(conjunct)
+			if err != nil {
+				return "", err
+			}
+			asserts = append(asserts, term)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("(set-logic ALL)\n")
+	for _, decl := range st.declarations {
+		sb.WriteString(decl)
+		sb.WriteString("\n")
+	}
+	for _, assert := range append(st.assumptions, asserts...) {
+		fmt.Fprintf(&sb, "(assert %s)\n", assert)
+	}
+	sb.WriteString("(check-sat)\n")
+	return sb.String(), nil
+}
+
+// conjuncts разбивает вложенные конъюнкции на отдельные условия
+func conjuncts(cond symbolic.SymbolicExpression) []symbolic.SymbolicExpression {
+	and, ok := cond.(*symbolic.LogicalOperation)
+	if !ok || and.Operator != symbolic.AND {
+		return []symbolic.SymbolicExpression{cond}
+	}
+
+	var res []symbolic.SymbolicExpression
+	for _, operand := range and.Operands {
+		res = append(res, conjuncts(operand)...)
+	}
+	return res
+}
+
+// term проверяет и транслирует выражение
+func (st *SMTLib2Translator) term(expr symbolic.SymbolicExpression) (res string, err error) {
+	if err := symbolic.Validate(expr); err != nil {
+		return "", NewTranslationError(err.Error(), expr)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			te, ok := r.(*TranslationError)
+			if !ok {
+				panic(r)
+			}
+			err = te
+		}
+	}()
+	return st.visit(expr), nil
+}
+
+func (st *SMTLib2Translator) visit(expr symbolic.SymbolicExpression) string {
+	if _, ok := expr.(*symbolic.Ref); ok {
+		panic(NewTranslationError("references are not supported", expr))
+	}
+	return expr.Accept(st).(string)
+}
+
+// VisitVariable объявляет переменную и возвращает её имя
+func (st *SMTLib2Translator) VisitVariable(expr *symbolic.SymbolicVariable) interface{} {
+	name := smtSymbol(expr.Name)
+	sort := st.variableSort(expr)
+	if st.declare(name, fmt.Sprintf("(declare-fun %s () %s)", name, sort)) && expr.ExprType.IsSequence() {
+		if st.stringBound > 0 {
+			st.assumptions = append(st.assumptions, app("<=", app("str.len", name), fmt.Sprint(st.stringBound)))
+		}
+		st.assumptions = append(st.assumptions,
+			app("str.in_re", name, app("re.*", app("re.range", `"\u{0}"`, `"\u{ff}"`))))
+	}
+	return name
+}
+
+// VisitIntConstant транслирует целочисленную константу в битовый вектор ширины её типа
+func (st *SMTLib2Translator) VisitIntConstant(expr *symbolic.IntConstant) interface{} {
+	bits := expr.Type().Bits()
+	value := uint64(expr.Value)
+	if bits < 64 {
+		value &= 1<<bits - 1
+	}
+	return fmt.Sprintf("#x%0*x", bits/4, value)
+}
+
+// VisitBoolConstant транслирует булеву константу
+func (st *SMTLib2Translator) VisitBoolConstant(expr *symbolic.BoolConstant) interface{} {
+	return fmt.Sprintf("%t", expr.Value)
+}
+
+// VisitFloatConstant транслирует константу с плавающей точкой точно, по битам её представления
+func (st *SMTLib2Translator) VisitFloatConstant(expr *symbolic.FloatConstant) interface{} {
+	if expr.Type() == symbolic.Float32Type {
+		bits := uint64(math.Float32bits(float32(expr.Value)))
+		return fmt.Sprintf("(fp #b%01b #b%08b #b%023b)", bits>>31, bits>>23&0xff, bits&(1<<23-1))
+	}
+	bits := math.Float64bits(expr.Value)
+	return fmt.Sprintf("(fp #b%01b #b%011b #b%052b)", bits>>63, bits>>52&0x7ff, bits&(1<<52-1))
+}
+
+// VisitStringConstant транслирует строку в строковый литерал SMT-LIB2.
+// Непечатаемые байты и обратная косая черта записываются как \u{..}, кавычка удваивается.
+func (st *SMTLib2Translator) VisitStringConstant(expr *symbolic.StringConstant) interface{} {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(expr.Value); i++ {
+		switch b := expr.Value[i]; {
+		case b == '"':
+			sb.WriteString(`""`)
+		case b < 0x20 || b > 0x7e || b == '\\':
+			fmt.Fprintf(&sb, `\u{%x}`, b)
+		default:
+			sb.WriteByte(b)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// VisitBinaryOperation транслирует бинарную операцию.
+// Операции над целыми выбираются по знаковости типа операндов, как в Z3Translator.
+func (st *SMTLib2Translator) VisitBinaryOperation(expr *symbolic.BinaryOperation) interface{} {
+	if expr.Left.Type().IsFloat() || expr.Right.Type().IsFloat() {
+		return st.floatOperation(expr)
+	}
+	if expr.Left.Type().IsSequence() {
+		return st.stringOperation(expr)
+	}
+
+	left, right := st.visit(expr.Left), st.visit(expr.Right)
+	signed := expr.Left.Type().IsSigned()
+	pick := func(s, u string) string {
+		if signed {
+			return s
+		}
+		return u
+	}
+
+	switch expr.Operator {
+	case symbolic.EQ:
+		return app("=", left, right)
+	case symbolic.NE:
+		return app("not", app("=", left, right))
+	}
+
+	if expr.Left.Type() == symbolic.BoolType {
+		panic(NewTranslationError("unsupported boolean operation", expr))
+	}
+
+	switch expr.Operator {
+	case symbolic.SHL, symbolic.SHR:
+		return st.shift(expr, left, right)
+	case symbolic.ADD:
+		return app("bvadd", left, right)
+	case symbolic.SUB:
+		return app("bvsub", left, right)
+	case symbolic.MUL:
+		return app("bvmul", left, right)
+	case symbolic.DIV:
+		return app(pick("bvsdiv", "bvudiv"), left, right)
+	case symbolic.MOD:
+		return app(pick("bvsrem", "bvurem"), left, right)
+	case symbolic.LT:
+		return app(pick("bvslt", "bvult"), left, right)
+	case symbolic.LE:
+		return app(pick("bvsle", "bvule"), left, right)
+	case symbolic.GT:
+		return app(pick("bvsgt", "bvugt"), left, right)
+	case symbolic.GE:
+		return app(pick("bvsge", "bvuge"), left, right)
+	case symbolic.BAND:
+		return app("bvand", left, right)
+	case symbolic.BOR:
+		return app("bvor", left, right)
+	case symbolic.BXOR:
+		return app("bvxor", left, right)
+	}
+	panic(NewTranslationError("unsupported operation", expr))
+}
+
+// floatOperation транслирует операцию по IEEE 754 с округлением к ближайшему чётному.
+// Целочисленный операнд преобразуется к сорту другого операнда.
+func (st *SMTLib2Translator) floatOperation(expr *symbolic.BinaryOperation) string {
+	left := st.floatOperand(expr.Left, expr.Right.Type())
+	right := st.floatOperand(expr.Right, expr.Left.Type())
+
+	switch expr.Operator {
+	case symbolic.ADD:
+		return app("fp.add", "RNE", left, right)
+	case symbolic.SUB:
+		return app("fp.sub", "RNE", left, right)
+	case symbolic.MUL:
+		return app("fp.mul", "RNE", left, right)
+	case symbolic.DIV:
+		return app("fp.div", "RNE", left, right)
+	case symbolic.EQ:
+		return app("fp.eq", left, right)
+	case symbolic.NE:
+		return app("not", app("fp.eq", left, right))
+	case symbolic.LT:
+		return app("fp.lt", left, right)
+	case symbolic.LE:
+		return app("fp.leq", left, right)
+	case symbolic.GT:
+		return app("fp.gt", left, right)
+	case symbolic.GE:
+		return app("fp.geq", left, right)
+	}
+	panic(NewTranslationError("unsupported floating-point operation", expr))
+}
+
+func (st *SMTLib2Translator) floatOperand(expr symbolic.SymbolicExpression, other symbolic.ExpressionType) string {
+	if !expr.Type().IsInteger() {
+		return st.visit(expr)
+	}
+	return intToFloat(st.visit(expr), expr.Type(), other)
+}
+
+// shift транслирует сдвиг так же, как Z3Translator: операнды расширяются до общей ширины,
+// чтобы счётчик не меньше ширины левого операнда давал 0 или -1
+func (st *SMTLib2Translator) shift(expr *symbolic.BinaryOperation, left, count string) string {
+	bits := expr.Left.Type().Bits()
+	countBits := expr.Right.Type().Bits()
+	width := max(bits, countBits)
+
+	signed := expr.Left.Type().IsSigned()
+	if bits < width {
+		if signed && expr.Operator == symbolic.SHR {
+			left = app(fmt.Sprintf("(_ sign_extend %d)", width-bits), left)
+		} else {
+			left = app(fmt.Sprintf("(_ zero_extend %d)", width-bits), left)
+		}
+	}
+	if countBits < width {
+		count = app(fmt.Sprintf("(_ zero_extend %d)", width-countBits), count)
+	}
+
+	var res string
+	switch {
+	case expr.Operator == symbolic.SHL:
+		res = app("bvshl", left, count)
+	case signed:
+		res = app("bvashr", left, count)
+	default:
+		res = app("bvlshr", left, count)
+	}
+
+	if bits < width {
+		res = app(fmt.Sprintf("(_ extract %d 0)", bits-1), res)
+	}
+	return res
+}
+
+// stringOperation транслирует конкатенацию и лексикографические сравнения строк
+func (st *SMTLib2Translator) stringOperation(expr *symbolic.BinaryOperation) string {
+	left, right := st.visit(expr.Left), st.visit(expr.Right)
+
+	switch expr.Operator {
+	case symbolic.ADD:
+		return app("str.++", left, right)
+	case symbolic.EQ:
+		return app("=", left, right)
+	case symbolic.NE:
+		return app("not", app("=", left, right))
+	case symbolic.LT:
+		return app("str.<", left, right)
+	case symbolic.LE:
+		return app("str.<=", left, right)
+	case symbolic.GT:
+		return app("str.<", right, left)
+	case symbolic.GE:
+		return app("str.<=", right, left)
+	}
+	panic(NewTranslationError("unsupported string operation", expr))
+}
+
+// VisitUnaryOperation транслирует побитовое отрицание
+func (st *SMTLib2Translator) VisitUnaryOperation(expr *symbolic.UnaryOperation) interface{} {
+	switch expr.Operator {
+	case symbolic.BNOT:
+		return app("bvnot", st.visit(expr.Left))
+	}
+	panic(NewTranslationError("unsupported operation", expr))
+}
+
+// VisitLogicalOperation транслирует логическую операцию
+func (st *SMTLib2Translator) VisitLogicalOperation(expr *symbolic.LogicalOperation) interface{} {
+	operands := make([]string, len(expr.Operands))
+	for i, operand := range expr.Operands {
+		operands[i] = st.visit(operand)
+	}
+
+	switch expr.Operator {
+	case symbolic.AND:
+		return app("and", operands...)
+	case symbolic.OR:
+		return app("or", operands...)
+	case symbolic.NOT:
+		return app("not", operands...)
+	case symbolic.IMPLIES:
+		return app("=>", operands...)
+	}
+	panic(NewTranslationError("unsupported operation", expr))
+}
+
+// VisitConditionalExpression транслирует условное выражение в ite
+func (st *SMTLib2Translator) VisitConditionalExpression(expr *symbolic.ConditionalExpression) interface{} {
+	return app("ite", st.visit(expr.Condition), st.visit(expr.Then), st.visit(expr.Else))
+}
+
+// VisitArraySelect транслирует чтение элемента массива в select
+func (st *SMTLib2Translator) VisitArraySelect(expr *symbolic.ArraySelect) interface{} {
+	return app("select", st.visit(expr.Array), st.visit(expr.Index))
+}
+
+// VisitArrayStore транслирует запись в массив в store
+func (st *SMTLib2Translator) VisitArrayStore(expr *symbolic.ArrayStore) interface{} {
+	return app("store", st.visit(expr.Array), st.visit(expr.Index), st.visit(expr.Value))
+}
+
+// VisitFunctionApplication объявляет неинтерпретируемую функцию и транслирует её применение
+func (st *SMTLib2Translator) VisitFunctionApplication(expr *symbolic.FunctionApplication) interface{} {
+	name := smtSymbol(expr.Function.Name)
+	params := make([]string, len(expr.Function.Params))
+	for i, param := range expr.Function.Params {
+		params[i] = st.sort(param)
+	}
+	st.declare(name, fmt.Sprintf("(declare-fun %s (%s) %s)", name, strings.Join(params, " "), st.sort(expr.Function.Result)))
+
+	if len(expr.Args) == 0 {
+		return name
+	}
+	args := make([]string, len(expr.Args))
+	for i, arg := range expr.Args {
+		args[i] = st.visit(arg)
+	}
+	return app(name, args...)
+}
+
+// VisitStringOperation транслирует операцию над строкой или срезом байт.
+// Индексы и длины переводятся между битовыми векторами и целыми числами теории строк
+// через bv2nat и int2bv; отрицательный индекс при этом становится заведомо вне строки.
+// Границы вне строки дают те же результаты, что и в Z3Translator: байт 0 и усечённый срез.
+func (st *SMTLib2Translator) VisitStringOperation(expr *symbolic.StringOperation) interface{} {
+	operands := make([]string, len(expr.Operands))
+	for i, operand := range expr.Operands {
+		operands[i] = st.visit(operand)
+	}
+
+	switch expr.Operator {
+	case symbolic.LEN:
+		return app("(_ int2bv 64)", app("str.len", operands[0]))
+	case symbolic.AT:
+		// Вне строки str.at даёт "" с кодом -1; как и Z3Translator, возвращаем байт 0
+		char := app("str.at", operands[0], app("bv2nat", operands[1]))
+		return app("ite", app("=", char, `""`), "#x00", app("(_ int2bv 8)", app("str.to_code", char)))
+	case symbolic.SLICE:
+		low, high := app("bv2nat", operands[1]), app("bv2nat", operands[2])
+		return app("str.substr", operands[0], low, app("-", high, low))
+	case symbolic.HAS_PREFIX:
+		return app("str.prefixof", operands[1], operands[0])
+	case symbolic.HAS_SUFFIX:
+		return app("str.suffixof", operands[1], operands[0])
+	case symbolic.CONTAINS:
+		return app("str.contains", operands[0], operands[1])
+	case symbolic.INDEX:
+		return app("(_ int2bv 64)", app("str.indexof", operands[0], operands[1], "0"))
+	case symbolic.TO_BYTES, symbolic.FROM_BYTES:
+		// Строка и срез байт имеют один сорт
+		return operands[0]
+	}
+	panic(NewTranslationError("unsupported string operation", expr))
+}
+
+// VisitConversion транслирует преобразование типа с семантикой Go, как Z3Translator
+func (st *SMTLib2Translator) VisitConversion(expr *symbolic.Conversion) interface{} {
+	source, target := expr.Operand.Type(), expr.Target
+	value := st.visit(expr.Operand)
+	if source == target {
+		return value
+	}
+
+	switch {
+	case source.IsInteger() && target.IsInteger():
+		switch {
+		case source.Bits() < target.Bits() && source.IsSigned():
+			return app(fmt.Sprintf("(_ sign_extend %d)", target.Bits()-source.Bits()), value)
+		case source.Bits() < target.Bits():
+			return app(fmt.Sprintf("(_ zero_extend %d)", target.Bits()-source.Bits()), value)
+		case source.Bits() > target.Bits():
+			return app(fmt.Sprintf("(_ extract %d 0)", target.Bits()-1), value)
+		}
+		return value
+	case source.IsInteger():
+		return intToFloat(value, source, target)
+	case target.IsInteger():
+		if target.IsSigned() {
+			return app(fmt.Sprintf("(_ fp.to_sbv %d)", target.Bits()), "RTZ", value)
+		}
+		return app(fmt.Sprintf("(_ fp.to_ubv %d)", target.Bits()), "RTZ", value)
+	default:
+		return app("(_ to_fp "+floatSortParams(target)+")", "RNE", value)
+	}
+}
+
+// Вспомогательные функции
+
+// declare добавляет объявление символа, если он ещё не объявлен, и сообщает, было ли оно добавлено.
+// Повторное объявление символа с другой сигнатурой - ошибка: SMT-LIB2 не допускает перегрузки.
+func (st *SMTLib2Translator) declare(name, declaration string) bool {
+	if previous, ok := st.declared[name]; ok {
+		if previous != declaration {
+			panic(NewTranslationError(fmt.Sprintf("conflicting declarations of %s", name), nil))
+		}
+		return false
+	}
+	st.declared[name] = declaration
+	st.declarations = append(st.declarations, declaration)
+	return true
+}
+
+func (st *SMTLib2Translator) variableSort(expr *symbolic.SymbolicVariable) string {
+	if expr.ExprType == symbolic.ArrayType {
+		return fmt.Sprintf("(Array %s %s)", st.sort(expr.Sort.Index), st.sort(expr.Sort.Element))
+	}
+	if expr.ExprType.IsSequence() {
+		return "String"
+	}
+	return st.sort(expr.ExprType)
+}
+
+// sort возвращает сорт SMT-LIB2 для скалярного типа элемента массива, параметра или результата функции.
+// Строки допустимы только в переменных: ограничения на их байты (см. Assumptions) накладываются
+// при объявлении переменной, а Z3Translator строки в массивах и функциях не поддерживает.
+func (st *SMTLib2Translator) sort(exprType symbolic.ExpressionType) string {
+	switch {
+	case exprType.IsInteger():
+		return fmt.Sprintf("(_ BitVec %d)", exprType.Bits())
+	case exprType.IsFloat():
+		return "(_ FloatingPoint " + floatSortParams(exprType) + ")"
+	case exprType == symbolic.BoolType:
+		return "Bool"
+	}
+	panic(NewTranslationError(fmt.Sprintf("unsupported sort %s", exprType), nil))
+}
+
+// floatSortParams возвращает ширины экспоненты и мантиссы: binary32 или binary64
+func floatSortParams(exprType symbolic.ExpressionType) string {
+	if exprType == symbolic.Float32Type {
+		return "8 24"
+	}
+	return "11 53"
+}
+
+// intToFloat преобразует битовый вектор типа source к сорту типа target с учётом знаковости
+func intToFloat(value string, source, target symbolic.ExpressionType) string {
+	if source.IsSigned() {
+		return app("(_ to_fp "+floatSortParams(target)+")", "RNE", value)
+	}
+	return app("(_ to_fp_unsigned "+floatSortParams(target)+")", "RNE", value)
+}
+
+// app записывает применение функции: (f a b ...)
+func app(fn string, args ...string) string {
+	return "(" + fn + " " + strings.Join(args, " ") + ")"
+}
+
+// smtSymbol возвращает имя в виде символа SMT-LIB2, заключая его в |...|,
+// если оно содержит недопустимые для простого символа знаки. Отображение инъективно:
+// знаки %, | и \ записываются как %XX, а к зарезервированным словам и именам функций теорий,
+// которые в SMT-LIB2 нельзя переопределить даже в |...|, добавляется префикс %.
+func smtSymbol(name string) string {
+	escaped := name
+	if strings.ContainsAny(name, `%|\`) {
+		escaped = strings.NewReplacer("%", "%25", "|", "%7c", `\`, "%5c").Replace(name)
+	} else if smtReserved(name) {
+		escaped = "%" + name
+	}
+
+	simple := escaped != "" && (escaped[0] < '0' || escaped[0] > '9')
+	for _, r := range escaped {
+		if !strings.ContainsRune("~!@$%^&*_-+=<>.?/", r) &&
+			!(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			simple = false
+			break
+		}
+	}
+	if simple {
+		return escaped
+	}
+	return "|" + escaped + "|"
+}
+
+// smtReservedNames - зарезервированные слова, команды и функции теорий, которые использует транслятор
+var smtReservedNames = map[string]bool{
+	"!": true, "_": true, "as": true, "exists": true, "forall": true, "let": true, "match": true, "par": true,
+	"BINARY": true, "DECIMAL": true, "HEXADECIMAL": true, "NUMERAL": true, "STRING": true,
+	"assert": true, "check-sat": true, "declare-fun": true, "declare-const": true, "define-fun": true,
+	"set-logic": true, "set-option": true, "get-model": true, "push": true, "pop": true, "exit": true,
+	"true": true, "false": true, "not": true, "and": true, "or": true, "xor": true, "=>": true, "=": true,
+	"distinct": true, "ite": true, "select": true, "store": true, "concat": true, "extract": true,
+	"int2bv": true, "bv2nat": true, "fp": true, "NaN": true, "+oo": true, "-oo": true, "+zero": true, "-zero": true,
+	"RNE": true, "RNA": true, "RTP": true, "RTN": true, "RTZ": true,
+	"+": true, "-": true, "*": true, "<": true, "<=": true, ">": true, ">=": true,
+}
+
+// smtReserved сообщает, совпадает ли имя с зарезервированным словом или функцией теории
+func smtReserved(name string) bool {
+	if smtReservedNames[name] {
+		return true
+	}
+	for _, prefix := range []string{"bv", "fp.", "to_fp", "str.", "re.", "seq."} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
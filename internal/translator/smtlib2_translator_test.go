@@ -0,0 +1,224 @@
+package translator
+
+import (
+	"errors"
+	"testing"
+
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/pkg/z3wrapper"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+func TestSMTLib2Terms(t *testing.T) {
+	c := symbolic.NewTypedIntConstant
+	op := symbolic.NewBinaryOperation
+	x := symbolic.NewSymbolicVariable("x", symbolic.Int8Type)
+	u := symbolic.NewSymbolicVariable("u", symbolic.UintType)
+	s := symbolic.NewSymbolicVariable("s", symbolic.StringType)
+	a := symbolic.NewArrayVariable("a", symbolic.ArraySort{Index: symbolic.IntType, Element: symbolic.BoolType})
+	h := symbolic.NewFunctionDeclaration("h", symbolic.IntType, symbolic.IntType)
+
+	tests := []struct {
+		name     string
+		expr     symbolic.SymbolicExpression
+		expected string
+	}{
+		{"signed division", op(x, c(-1, symbolic.Int8Type), symbolic.DIV), "(bvsdiv x #xff)"},
+		{"unsigned comparison", op(u, c(1, symbolic.UintType), symbolic.LT), "(bvult u #x0000000000000001)"},
+		{"not equal", op(x, x, symbolic.NE), "(not (= x x))"},
+		{"narrow shift", op(x, u, symbolic.SHR), "((_ extract 7 0) (bvashr ((_ sign_extend 56) x) u))"},
+		{"bitwise not", symbolic.NewUnaryOperation(x, symbolic.BNOT), "(bvnot x)"},
+		{"float", op(symbolic.NewFloatConstant(1), symbolic.NewFloatConstant(-2), symbolic.ADD),
+			"(fp.add RNE (fp #b0 #b01111111111 #b0000000000000000000000000000000000000000000000000000) " +
+				"(fp #b1 #b10000000000 #b0000000000000000000000000000000000000000000000000000))"},
+		{"mixed float", op(u, symbolic.NewTypedFloatConstant(0.5, symbolic.Float32Type), symbolic.LT),
+			"(fp.lt ((_ to_fp_unsigned 8 24) RNE u) (fp #b0 #b01111110 #b00000000000000000000000))"},
+		{"logical", symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+			op(x, x, symbolic.EQ), symbolic.NewBoolConstant(false),
+		}, symbolic.IMPLIES), "(=> (= x x) false)"},
+		{"ite", symbolic.NewConditionalExpression(op(x, x, symbolic.EQ), x, c(0, symbolic.Int8Type)), "(ite (= x x) x #x00)"},
+		{"array", symbolic.NewArraySelect(symbolic.NewArrayStore(a, symbolic.NewIntConstant(1), symbolic.NewBoolConstant(true)), symbolic.NewIntConstant(2)),
+			"(select (store a #x0000000000000001 true) #x0000000000000002)"},
+		{"function", symbolic.NewFunctionApplication(h, symbolic.NewConversion(x, symbolic.IntType)), "(h ((_ sign_extend 56) x))"},
+		{"truncation", symbolic.NewConversion(u, symbolic.Uint16Type), "((_ extract 15 0) u)"},
+		{"float to int", symbolic.NewConversion(symbolic.NewFloatConstant(0), symbolic.Int32Type),
+			"((_ fp.to_sbv 32) RTZ (fp #b0 #b00000000000 #b0000000000000000000000000000000000000000000000000000))"},
+		{"string literal", op(s, symbolic.NewStringConstant("a\"\\\n\xff"), symbolic.ADD), `(str.++ s "a""\u{5c}\u{a}\u{ff}")`},
+		{"string comparison", op(s, s, symbolic.GE), "(str.<= s s)"},
+		{"byte at", symbolic.NewStringOperation(symbolic.AT, s, symbolic.NewIntConstant(0)),
+			`(ite (= (str.at s (bv2nat #x0000000000000000)) "") #x00 ((_ int2bv 8) (str.to_code (str.at s (bv2nat #x0000000000000000)))))`},
+		{"prefix", symbolic.NewStringOperation(symbolic.HAS_PREFIX, s, symbolic.NewStringConstant("ab")), `(str.prefixof "ab" s)`},
+		{"quoted name", symbolic.NewSymbolicVariable("f#1.x", symbolic.BoolType), "|f#1.x|"},
+		{"escaped bar", symbolic.NewSymbolicVariable("a|b", symbolic.BoolType), "a%7cb"},
+		{"escaped backslash", symbolic.NewSymbolicVariable(`a\b`, symbolic.BoolType), "a%5cb"},
+		{"escaped percent", symbolic.NewSymbolicVariable("%and", symbolic.BoolType), "%25and"},
+		{"reserved word", symbolic.NewSymbolicVariable("assert", symbolic.BoolType), "%assert"},
+		{"theory function", symbolic.NewSymbolicVariable("bv2nat", symbolic.BoolType), "%bv2nat"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term, err := NewSMTLib2Translator().TranslateExpression(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if term != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, term)
+			}
+		})
+	}
+}
+
+func TestSMTLib2Script(t *testing.T) {
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	s := symbolic.NewSymbolicVariable("s", symbolic.StringType)
+	zero := symbolic.NewIntConstant(0)
+
+	pc := symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(x, zero, symbolic.GT),
+		symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+			symbolic.NewStringOperation(symbolic.CONTAINS, s, symbolic.NewStringConstant("go")),
+			symbolic.NewBinaryOperation(symbolic.NewStringOperation(symbolic.LEN, s), x, symbolic.EQ),
+		}, symbolic.AND),
+	}, symbolic.AND)
+
+	st := NewSMTLib2Translator()
+	st.SetStringBound(8)
+	script, err := st.Script(pc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `(set-logic ALL)
+(declare-fun x () (_ BitVec 64))
+(declare-fun s () String)
+(assert (<= (str.len s) 8))
+(assert (str.in_re s (re.* (re.range "\u{0}" "\u{ff}"))))
+(assert (bvsgt x #x0000000000000000))
+(assert (str.contains s "go"))
+(assert (= ((_ int2bv 64) (str.len s)) x))
+(check-sat)
+`
+	if script != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, script)
+	}
+
+	if _, err := st.Script(x); err == nil {
+		t.Error("expected an error for a non-boolean assertion")
+	}
+
+	// Символ x уже объявлен как битовый вектор, объявить его заново с другой сигнатурой нельзя
+	redeclared := symbolic.NewFunctionApplication(symbolic.NewFunctionDeclaration("x", symbolic.BoolType))
+	if _, err := st.TranslateExpression(redeclared); err == nil {
+		t.Error("expected an error for a conflicting declaration of x")
+	}
+
+	invalid := &symbolic.BinaryOperation{Left: x, Right: symbolic.NewBoolConstant(true), Operator: symbolic.EQ}
+	_, err = st.TranslateExpression(invalid)
+	var te *TranslationError
+	if !errors.As(err, &te) {
+		t.Errorf("expected a translation error for %s, got %v", invalid, err)
+	}
+}
+
+// TestSMTLib2Solver выполняет сгенерированные скрипты в Z3 и сравнивает ответ
+// с результатом проверки той же формулы через Z3Translator
+func TestSMTLib2Solver(t *testing.T) {
+	op := symbolic.NewBinaryOperation
+	sop := symbolic.NewStringOperation
+	num := symbolic.NewIntConstant
+	x := symbolic.NewSymbolicVariable("x", symbolic.Int8Type)
+	n := symbolic.NewSymbolicVariable("n", symbolic.IntType)
+	f := symbolic.NewSymbolicVariable("f", symbolic.FloatType)
+	s := symbolic.NewSymbolicVariable("s", symbolic.StringType)
+	a := symbolic.NewArrayVariable("a", symbolic.ArraySort{Index: symbolic.IntType, Element: symbolic.Uint8Type})
+	h := symbolic.NewFunctionDeclaration("h", symbolic.IntType, symbolic.IntType)
+
+	tests := []struct {
+		name string
+		cond symbolic.SymbolicExpression
+		sat  bool
+	}{
+		{"int8 overflow", op(op(x, symbolic.NewTypedIntConstant(1, symbolic.Int8Type), symbolic.ADD), x, symbolic.LT), true},
+		{"no int8 above max", op(x, symbolic.NewConversion(num(127), symbolic.Int8Type), symbolic.GT), false},
+		{"float rounding", op(op(f, symbolic.NewFloatConstant(0.1), symbolic.ADD), symbolic.NewFloatConstant(0.3), symbolic.EQ), true},
+		{"NaN", op(f, f, symbolic.NE), true},
+		{"array store", op(symbolic.NewArraySelect(symbolic.NewArrayStore(a, n, symbolic.NewTypedIntConstant(7, symbolic.Uint8Type)), n),
+			symbolic.NewTypedIntConstant(7, symbolic.Uint8Type), symbolic.NE), false},
+		{"function", symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+			op(symbolic.NewFunctionApplication(h, n), num(1), symbolic.EQ),
+			op(symbolic.NewFunctionApplication(h, n), num(2), symbolic.EQ),
+		}, symbolic.AND), false},
+		{"string length", op(sop(symbolic.LEN, op(s, symbolic.NewStringConstant("go"), symbolic.ADD)), num(3), symbolic.EQ), true},
+		{"string bound", op(sop(symbolic.LEN, s), num(DefaultStringBound), symbolic.GT), false},
+		{"byte at", symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+			op(sop(symbolic.AT, s, num(0)), symbolic.NewTypedIntConstant(200, symbolic.ByteType), symbolic.EQ),
+			sop(symbolic.HAS_PREFIX, s, symbolic.NewStringConstant("a")),
+		}, symbolic.AND), false},
+		{"index", op(sop(symbolic.INDEX, s, symbolic.NewStringConstant("=")), num(2), symbolic.EQ), true},
+		{"byte out of range", symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+			op(n, num(2), symbolic.GT),
+			op(sop(symbolic.AT, symbolic.NewStringConstant("abc"), n), symbolic.NewTypedIntConstant(0, symbolic.ByteType), symbolic.NE),
+		}, symbolic.AND), false},
+		{"negative index", op(sop(symbolic.AT, symbolic.NewStringConstant("\xff"), num(-1)),
+			symbolic.NewTypedIntConstant(0, symbolic.ByteType), symbolic.EQ), true},
+		{"non-ASCII bytes", symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+			op(s, symbolic.NewStringConstant("é"), symbolic.EQ),
+			op(sop(symbolic.LEN, s), num(2), symbolic.EQ),
+			op(sop(symbolic.AT, s, num(1)), symbolic.NewTypedIntConstant(0xa9, symbolic.ByteType), symbolic.EQ),
+		}, symbolic.AND), true},
+		{"slice past the end", op(sop(symbolic.LEN, sop(symbolic.SLICE, symbolic.NewStringConstant("abc"), num(1), n)), num(2), symbolic.GT), false},
+		{"empty slice", op(sop(symbolic.LEN, sop(symbolic.SLICE, symbolic.NewStringConstant("abc"), num(2), num(1))), num(0), symbolic.NE), false},
+		{"distinct escaped names", symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+			symbolic.NewSymbolicVariable("a|b", symbolic.BoolType),
+			symbolic.NewSymbolicVariable("a_b", symbolic.BoolType),
+			symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{symbolic.NewSymbolicVariable(`a\b`, symbolic.BoolType)}, symbolic.NOT),
+		}, symbolic.AND), true},
+		{"variable named after a theory function", op(sop(symbolic.AT, s, symbolic.NewSymbolicVariable("bv2nat", symbolic.IntType)),
+			symbolic.NewTypedIntConstant(1, symbolic.ByteType), symbolic.EQ), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := NewSMTLib2Translator()
+			script, err := st.Script(tt.cond)
+			if err != nil {
+				t.Fatal(err)
+			}
+			output, err := z3wrapper.RunSMTLib2(script)
+			if err != nil {
+				t.Fatalf("%v\n%s", err, script)
+			}
+			expected := map[bool]string{true: "sat", false: "unsat"}[tt.sat]
+			if output != expected {
+				t.Errorf("expected %s, got %s\n%s", expected, output, script)
+			}
+
+			zt := NewZ3Translator()
+			translated, err := zt.TranslateExpression(tt.cond)
+			if err != nil {
+				t.Fatal(err)
+			}
+			solver := z3.NewSolver(zt.ctx)
+			for _, assumption := range zt.Assumptions() {
+				solver.Assert(assumption)
+			}
+			solver.Assert(translated.(z3.Bool))
+			if sat, err := solver.Check(); err != nil || sat != tt.sat {
+				t.Errorf("Z3Translator: expected sat = %t, got %t (%v)", tt.sat, sat, err)
+			}
+		})
+	}
+
+	// Без границы длины строка может быть длиннее DefaultStringBound
+	st := NewSMTLib2Translator()
+	st.SetStringBound(0)
+	script, err := st.Script(op(sop(symbolic.LEN, s), num(DefaultStringBound), symbolic.GT))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output, err := z3wrapper.RunSMTLib2(script); err != nil || output != "sat" {
+		t.Errorf("expected an unbounded string to be longer than %d: %s %v\n%s", DefaultStringBound, output, err, script)
+	}
+}
@@ -0,0 +1,275 @@
+package translator
+
+import (
+	"fmt"
+	"symbolic-execution-course/internal/symbolic"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+// DefaultStringBound - максимальная длина символьных строк и срезов байт по умолчанию
+const DefaultStringBound = 32
+
+// z3String - представление строки или среза байт в Z3.
+// Строка кодируется ограниченной последовательностью байт: массивом 8-битных векторов
+// ёмкости len(bytes) и 64-битной длиной. Байты за пределами длины не влияют на результат операций.
+// Длина констант и результатов операций над ними точна, а длина символьных переменных
+// не превышает StringBound (см. Assumptions).
+//
+// Это ограничение кодировки, а не семантики Go: решения со строками длиннее StringBound
+// не находятся, и ответ "unsat" означает лишь отсутствие решений в пределах границы.
+// Используемая привязка Z3 не предоставляет ни сорт последовательностей, ни кванторы,
+// а без кванторов неограниченный массив байт с длиной не выражает равенство, конкатенацию
+// и поиск подстроки. Check поэтому перепроверяет такой "unsat" в теории строк Z3
+// через C API (SMTLib2Translator с SetStringBound(0)) и возвращает Unknown, если ответа нет.
+type z3String struct {
+	bytes  []z3.BV
+	length z3.BV
+}
+
+// SetStringBound задаёт максимальную длину символьных строковых переменных.
+// Чем больше граница, тем больше формулы: большинство операций квадратичны по ёмкости.
+func (zt *Z3Translator) SetStringBound(bound int) {
+	zt.stringBound = bound
+}
+
+// Assumptions возвращает ограничения, которые должны выполняться вместе с любой
+// оттранслированной формулой: границы длин символьных строк. Их нужно добавить
+// в решатель отдельно, а не в проверяемую формулу, иначе отрицание формулы
+// станет выполнимым за счёт нарушения границ.
+func (zt *Z3Translator) Assumptions() []z3.Bool {
+	return zt.assumptions
+}
+
+// VisitStringConstant транслирует строковую константу в последовательность байт
+func (zt *Z3Translator) VisitStringConstant(expr *symbolic.StringConstant) interface{} {
+	res := z3String{
+		bytes:  make([]z3.BV, len(expr.Value)),
+		length: zt.intValue(len(expr.Value)),
+	}
+	for i := 0; i < len(expr.Value); i++ {
+		res.bytes[i] = zt.ctx.FromInt(int64(expr.Value[i]), zt.ctx.BVSort(8)).(z3.BV)
+	}
+	return res
+}
+
+// VisitStringOperation транслирует операцию над строками
+func (zt *Z3Translator) VisitStringOperation(expr *symbolic.StringOperation) interface{} {
+	operands := make([]interface{}, len(expr.Operands))
+	for i, operand := range expr.Operands {
+		operands[i] = operand.Accept(zt)
+	}
+
+	switch expr.Operator {
+	case symbolic.LEN:
+		return operands[0].(z3String).length
+	case symbolic.AT:
+		return zt.byteAt(operands[0].(z3String), operands[1].(z3.BV))
+	case symbolic.SLICE:
+		return zt.slice(operands[0].(z3String), operands[1].(z3.BV), operands[2].(z3.BV))
+	case symbolic.HAS_PREFIX:
+		s, prefix := operands[0].(z3String), operands[1].(z3String)
+		return zt.matchAt(s, prefix, zt.intValue(0))
+	case symbolic.HAS_SUFFIX:
+		s, suffix := operands[0].(z3String), operands[1].(z3String)
+		return suffix.length.SLE(s.length).And(zt.matchAt(s, suffix, s.length.Sub(suffix.length)))
+	case symbolic.CONTAINS:
+		s, substr := operands[0].(z3String), operands[1].(z3String)
+		res := zt.ctx.FromBool(false)
+		for i := 0; i <= len(s.bytes); i++ {
+			res = res.Or(zt.matchAt(s, substr, zt.intValue(i)))
+		}
+		return res
+	case symbolic.INDEX:
+		s, substr := operands[0].(z3String), operands[1].(z3String)
+		res := zt.intValue(-1)
+		for i := len(s.bytes); i >= 0; i-- {
+			res = zt.matchAt(s, substr, zt.intValue(i)).IfThenElse(zt.intValue(i), res).(z3.BV)
+		}
+		return res
+	case symbolic.TO_BYTES, symbolic.FROM_BYTES:
+		// string и []byte кодируются одинаково
+		return operands[0]
+	}
+	panic("not implemented")
+}
+
+// stringOperation транслирует конкатенацию и сравнения строк
+func (zt *Z3Translator) stringOperation(expr *symbolic.BinaryOperation) interface{} {
+	left := expr.Left.Accept(zt).(z3String)
+	right := expr.Right.Accept(zt).(z3String)
+
+	switch expr.Operator {
+	case symbolic.ADD:
+		return zt.concat(left, right)
+	case symbolic.EQ:
+		return zt.stringEq(left, right)
+	case symbolic.NE:
+		return zt.stringEq(left, right).Not()
+	case symbolic.LT:
+		return zt.stringLess(left, right)
+	case symbolic.LE:
+		return zt.stringLess(right, left).Not()
+	case symbolic.GT:
+		return zt.stringLess(right, left)
+	case symbolic.GE:
+		return zt.stringLess(left, right).Not()
+	}
+	panic("not implemented")
+}
+
+// stringIte выбирает одну из строк поэлементно. Ёмкость результата равна большей
+// из ёмкостей, недостающие байты меньшей строки считаются нулевыми.
+func (zt *Z3Translator) stringIte(cond z3.Bool, then, els z3String) z3String {
+	res := z3String{
+		bytes:  make([]z3.BV, max(len(then.bytes), len(els.bytes))),
+		length: cond.IfThenElse(then.length, els.length).(z3.BV),
+	}
+	zero := zt.ctx.FromInt(0, zt.ctx.BVSort(8)).(z3.BV)
+	for k := range res.bytes {
+		a, b := zero, zero
+		if k < len(then.bytes) {
+			a = then.bytes[k]
+		}
+		if k < len(els.bytes) {
+			b = els.bytes[k]
+		}
+		res.bytes[k] = cond.IfThenElse(a, b).(z3.BV)
+	}
+	return res
+}
+
+// stringVariable создаёт символьную строку ёмкости StringBound и ограничение на её длину
+func (zt *Z3Translator) stringVariable(name string) z3String {
+	if s, ok := zt.strings[name]; ok {
+		return s
+	}
+
+	res := z3String{
+		bytes:  make([]z3.BV, zt.stringBound),
+		length: zt.ctx.BVConst(name+"!len", 64),
+	}
+	for i := range res.bytes {
+		res.bytes[i] = zt.ctx.BVConst(fmt.Sprintf("%s!%d", name, i), 8)
+	}
+
+	zt.assumptions = append(zt.assumptions,
+		res.length.SGE(zt.intValue(0)),
+		res.length.SLE(zt.intValue(zt.stringBound)),
+	)
+	zt.strings[name] = res
+	return res
+}
+
+// stringValue возвращает значение строки в модели
+func (zt *Z3Translator) stringValue(model *z3.Model, s z3String) (string, error) {
+	length, _, ok := model.Eval(s.length, true).(z3.BV).AsInt64()
+	if !ok || length < 0 || length > int64(len(s.bytes)) {
+		return "", fmt.Errorf("invalid string length in model")
+	}
+
+	res := make([]byte, length)
+	for i := range res {
+		b, _, ok := model.Eval(s.bytes[i], true).(z3.BV).AsUint64()
+		if !ok {
+			return "", fmt.Errorf("invalid string byte in model")
+		}
+		res[i] = byte(b)
+	}
+	return string(res), nil
+}
+
+// intValue создаёт константу типа int
+func (zt *Z3Translator) intValue(value int) z3.BV {
+	return zt.ctx.FromInt(int64(value), zt.ctx.BVSort(64)).(z3.BV)
+}
+
+// byteAt возвращает байт по символьному индексу. Индекс вне строки даёт 0:
+// паника при выходе за границы не моделируется.
+func (zt *Z3Translator) byteAt(s z3String, index z3.BV) z3.BV {
+	zero := zt.ctx.FromInt(0, zt.ctx.BVSort(8)).(z3.BV)
+	res := zero
+	for i := len(s.bytes) - 1; i >= 0; i-- {
+		res = index.Eq(zt.intValue(i)).IfThenElse(s.bytes[i], res).(z3.BV)
+	}
+	// Байты между длиной и ёмкостью не ограничены, поэтому индекс сравнивается и с длиной
+	return index.ULT(s.length).IfThenElse(res, zero).(z3.BV)
+}
+
+// concat склеивает строки. Байт k результата берётся из left при k < len(left),
+// иначе из right по индексу k - len(left).
+func (zt *Z3Translator) concat(left, right z3String) z3String {
+	res := z3String{
+		bytes:  make([]z3.BV, len(left.bytes)+len(right.bytes)),
+		length: left.length.Add(right.length),
+	}
+
+	for k := range res.bytes {
+		fromRight := zt.ctx.FromInt(0, zt.ctx.BVSort(8)).(z3.BV)
+		for j := min(k, len(right.bytes)-1); j >= 0; j-- {
+			fromRight = left.length.Eq(zt.intValue(k-j)).IfThenElse(right.bytes[j], fromRight).(z3.BV)
+		}
+
+		if k < len(left.bytes) {
+			res.bytes[k] = zt.intValue(k).SLT(left.length).IfThenElse(left.bytes[k], fromRight).(z3.BV)
+		} else {
+			res.bytes[k] = fromRight
+		}
+	}
+	return res
+}
+
+// slice возвращает s[lo:hi]. Границы вне строки обрабатываются так же, как str.substr
+// в SMTLib2Translator: при lo >= len(s) или hi <= lo (без знака) результат пуст, а hi > len(s)
+// обрезается до длины строки. Паника Go при таких границах не моделируется.
+func (zt *Z3Translator) slice(s z3String, lo, hi z3.BV) z3String {
+	end := hi.ULE(s.length).IfThenElse(hi, s.length).(z3.BV)
+	empty := lo.UGE(s.length).Or(hi.ULE(lo))
+	res := z3String{
+		bytes:  make([]z3.BV, len(s.bytes)),
+		length: empty.IfThenElse(zt.intValue(0), end.Sub(lo)).(z3.BV),
+	}
+	for k := range res.bytes {
+		res.bytes[k] = zt.byteAt(s, lo.Add(zt.intValue(k)))
+	}
+	return res
+}
+
+// stringEq сравнивает длины и байты в пределах длины
+func (zt *Z3Translator) stringEq(left, right z3String) z3.Bool {
+	res := left.length.Eq(right.length)
+	for k := 0; k < min(len(left.bytes), len(right.bytes)); k++ {
+		inside := zt.intValue(k).SLT(left.length)
+		res = res.And(inside.Implies(left.bytes[k].Eq(right.bytes[k])))
+	}
+	return res
+}
+
+// stringLess сравнивает строки лексикографически по байтам, как оператор < в Go.
+// Позиции перебираются с конца, так что первой проверяется позиция 0:
+// закончившаяся строка меньше продолжающейся, иначе решает первый различный байт.
+func (zt *Z3Translator) stringLess(left, right z3String) z3.Bool {
+	res := zt.ctx.FromBool(false)
+	for k := max(len(left.bytes), len(right.bytes)); k >= 0; k-- {
+		pos := zt.intValue(k)
+		// За пределами ёмкости одной из строк совпадение байт невозможно:
+		// к этой позиции та строка уже закончилась
+		if k < len(left.bytes) && k < len(right.bytes) {
+			a, b := left.bytes[k], right.bytes[k]
+			res = a.ULT(b).Or(a.Eq(b).And(res))
+		}
+		res = pos.Eq(right.length).IfThenElse(zt.ctx.FromBool(false),
+			pos.Eq(left.length).IfThenElse(zt.ctx.FromBool(true), res)).(z3.Bool)
+	}
+	return res
+}
+
+// matchAt проверяет, что sub входит в s начиная с позиции at
+func (zt *Z3Translator) matchAt(s, sub z3String, at z3.BV) z3.Bool {
+	res := at.SGE(zt.intValue(0)).And(at.Add(sub.length).SLE(s.length))
+	for k := range sub.bytes {
+		inside := zt.intValue(k).SLT(sub.length)
+		res = res.And(inside.Implies(zt.byteAt(s, at.Add(zt.intValue(k))).Eq(sub.bytes[k])))
+	}
+	return res
+}
@@ -4,6 +4,7 @@ package translator
 import (
 	"fmt"
 	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/pkg/z3wrapper"
 
 	"github.com/ebukreev/go-z3/z3"
 )
@@ -12,18 +13,29 @@ import (
 type Z3Translator struct {
 	ctx    *z3.Context
 	config *z3.Config
-	vars   map[string]z3.Value // Кэш переменных
+	vars   map[string]z3.Value    // Кэш переменных
+	funcs  map[string]z3.FuncDecl // Кэш объявлений неинтерпретируемых функций по имени и сигнатуре
+
+	strings     map[string]z3String // Кэш строковых переменных
+	stringBound int                 // Максимальная длина строковых переменных
+	assumptions []z3.Bool           // Ограничения на длины строковых переменных
 }
 
 // NewZ3Translator создаёт новый экземпляр Z3 транслятора
 func NewZ3Translator() *Z3Translator {
 	config := &z3.Config{}
 	ctx := z3.NewContext(config)
+	// Арифметика с плавающей точкой в Go округляет к ближайшему чётному
+	ctx.SetRoundingMode(z3.RoundToNearestEven)
 
 	return &Z3Translator{
 		ctx:    ctx,
 		config: config,
 		vars:   make(map[string]z3.Value),
+		funcs:  make(map[string]z3.FuncDecl),
+
+		strings:     make(map[string]z3String),
+		stringBound: DefaultStringBound,
 	}
 }
 
@@ -35,6 +47,9 @@ func (zt *Z3Translator) GetContext() interface{} {
 // Reset сбрасывает состояние транслятора
 func (zt *Z3Translator) Reset() {
 	zt.vars = make(map[string]z3.Value)
+	zt.funcs = make(map[string]z3.FuncDecl)
+	zt.strings = make(map[string]z3String)
+	zt.assumptions = nil
 }
 
 // Close освобождает ресурсы
@@ -42,26 +57,45 @@ func (zt *Z3Translator) Close() {
 	// Z3 контекст закрывается автоматически
 }
 
-// TranslateExpression транслирует символьное выражение в Z3
-func (zt *Z3Translator) TranslateExpression(expr symbolic.SymbolicExpression) (interface{}, error) {
+// TranslateExpression транслирует символьное выражение в Z3. Выражение предварительно
+// проверяется symbolic.Validate, а паника при трансляции неподдерживаемой конструкции
+// возвращается как *TranslationError, поэтому выражения из непроверенных источников безопасны.
+func (zt *Z3Translator) TranslateExpression(expr symbolic.SymbolicExpression) (res interface{}, err error) {
+	if err := symbolic.Validate(expr); err != nil {
+		return nil, NewTranslationError(err.Error(), expr)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			te, ok := r.(*TranslationError)
+			if !ok {
+				te = NewTranslationError(fmt.Sprint(r), expr)
+			}
+			res, err = nil, te
+		}
+	}()
 	return expr.Accept(zt), nil
 }
 
 // VisitVariable транслирует символьную переменную в Z3
 func (zt *Z3Translator) VisitVariable(expr *symbolic.SymbolicVariable) interface{} {
+	if expr.ExprType.IsSequence() {
+		return zt.stringVariable(expr.Name)
+	}
+
 	v, ok := zt.vars[expr.Name]
 	if ok {
 		return v
 	}
 
-	v = zt.createZ3Variable(expr.Name, expr.ExprType)
+	v = zt.createZ3Variable(expr)
 	zt.vars[expr.Name] = v
 	return v
 }
 
-// VisitIntConstant транслирует целочисленную константу в Z3
+// VisitIntConstant транслирует целочисленную константу в Z3 как битовый вектор ширины её типа
 func (zt *Z3Translator) VisitIntConstant(expr *symbolic.IntConstant) interface{} {
-	return zt.ctx.FromInt(expr.Value, zt.ctx.BVSort(64))
+	return zt.ctx.FromInt(expr.Value, zt.ctx.BVSort(expr.Type().Bits()))
 }
 
 // VisitBoolConstant транслирует булеву константу в Z3
@@ -69,14 +103,32 @@ func (zt *Z3Translator) VisitBoolConstant(expr *symbolic.BoolConstant) interface
 	return zt.ctx.FromBool(expr.Value)
 }
 
+// VisitFloatConstant транслирует константу с плавающей точкой в Z3 с сортом её типа
 func (zt *Z3Translator) VisitFloatConstant(expr *symbolic.FloatConstant) interface{} {
-	return zt.ctx.FromFloat64(expr.Value, zt.ctx.FloatSort(11, 53))
+	if expr.Type() == symbolic.Float32Type {
+		return zt.ctx.FromFloat32(float32(expr.Value), zt.floatSort(expr.Type()))
+	}
+	return zt.ctx.FromFloat64(expr.Value, zt.floatSort(expr.Type()))
 }
 
-// VisitBinaryOperation транслирует бинарную операцию в Z3
+// VisitBinaryOperation транслирует бинарную операцию в Z3.
+// Деление, остаток, сравнения и сдвиг вправо выбираются по знаковости типа операндов,
+// как в Go: остаток имеет знак делимого, поэтому используется SRem, а не SMod.
 func (zt *Z3Translator) VisitBinaryOperation(expr *symbolic.BinaryOperation) interface{} {
+	if expr.Left.Type().IsFloat() || expr.Right.Type().IsFloat() {
+		return zt.floatOperation(expr)
+	}
+	if expr.Left.Type().IsSequence() {
+		return zt.stringOperation(expr)
+	}
+
+	if expr.Left.Type() == symbolic.BoolType {
+		return zt.boolOperation(expr)
+	}
+
 	left := expr.Left.Accept(zt).(z3.BV)
 	right := expr.Right.Accept(zt).(z3.BV)
+	signed := expr.Left.Type().IsSigned()
 
 	switch expr.Operator {
 	case symbolic.ADD:
@@ -84,27 +136,157 @@ func (zt *Z3Translator) VisitBinaryOperation(expr *symbolic.BinaryOperation) int
 	case symbolic.SUB:
 		return left.Sub(right)
 	case symbolic.DIV:
-		return left.SDiv(right)
+		if signed {
+			return left.SDiv(right)
+		}
+		return left.UDiv(right)
 	case symbolic.MUL:
 		return left.Mul(right)
 	case symbolic.MOD:
-		return left.SMod(right)
+		if signed {
+			return left.SRem(right)
+		}
+		return left.URem(right)
+	case symbolic.EQ:
+		return left.Eq(right)
+	case symbolic.NE:
+		return left.NE(right)
+	case symbolic.LT:
+		if signed {
+			return left.SLT(right)
+		}
+		return left.ULT(right)
+	case symbolic.LE:
+		if signed {
+			return left.SLE(right)
+		}
+		return left.ULE(right)
+	case symbolic.GT:
+		if signed {
+			return left.SGT(right)
+		}
+		return left.UGT(right)
+	case symbolic.GE:
+		if signed {
+			return left.SGE(right)
+		}
+		return left.UGE(right)
+	case symbolic.BAND:
+		return left.And(right)
+	case symbolic.BOR:
+		return left.Or(right)
+	case symbolic.BXOR:
+		return left.Xor(right)
+	case symbolic.SHL, symbolic.SHR:
+		return zt.shift(expr, left, right)
+	}
+	panic("not implemented")
+}
+
+// boolOperation транслирует сравнение булевых значений на равенство
+func (zt *Z3Translator) boolOperation(expr *symbolic.BinaryOperation) z3.Bool {
+	left := expr.Left.Accept(zt).(z3.Bool)
+	right := expr.Right.Accept(zt).(z3.Bool)
+
+	switch expr.Operator {
 	case symbolic.EQ:
 		return left.Eq(right)
 	case symbolic.NE:
 		return left.NE(right)
+	}
+	panic(NewTranslationError("unsupported boolean operation", expr))
+}
+
+// floatOperation транслирует операцию над числами с плавающей точкой по IEEE 754:
+// арифметика округляется к ближайшему чётному, сравнения с NaN ложны, а NaN != NaN.
+// Целочисленный операнд предварительно преобразуется к сорту другого операнда.
+func (zt *Z3Translator) floatOperation(expr *symbolic.BinaryOperation) z3.Value {
+	left := zt.floatOperand(expr.Left, expr.Right.Type())
+	right := zt.floatOperand(expr.Right, expr.Left.Type())
+
+	switch expr.Operator {
+	case symbolic.ADD:
+		return left.Add(right)
+	case symbolic.SUB:
+		return left.Sub(right)
+	case symbolic.MUL:
+		return left.Mul(right)
+	case symbolic.DIV:
+		return left.Div(right)
+	case symbolic.EQ:
+		return left.IEEEEq(right)
+	case symbolic.NE:
+		return left.IEEEEq(right).Not()
 	case symbolic.LT:
-		return left.SLT(right)
+		return left.LT(right)
 	case symbolic.LE:
-		return left.SLE(right)
+		return left.LE(right)
 	case symbolic.GT:
-		return left.SGT(right)
+		return left.GT(right)
 	case symbolic.GE:
-		return left.SGE(right)
+		return left.GE(right)
 	}
 	panic("not implemented")
 }
 
+// floatOperand транслирует операнд операции с плавающей точкой.
+// Целое число преобразуется к сорту типа other с учётом знаковости.
+func (zt *Z3Translator) floatOperand(expr symbolic.SymbolicExpression, other symbolic.ExpressionType) z3.Float {
+	if !expr.Type().IsInteger() {
+		return expr.Accept(zt).(z3.Float)
+	}
+
+	value := expr.Accept(zt).(z3.BV)
+	if expr.Type().IsSigned() {
+		return value.SToFloat(zt.floatSort(other))
+	}
+	return value.UToFloat(zt.floatSort(other))
+}
+
+// floatSort возвращает сорт Z3 для типа с плавающей точкой: binary32 или binary64
+func (zt *Z3Translator) floatSort(exprType symbolic.ExpressionType) z3.Sort {
+	if exprType == symbolic.Float32Type {
+		return zt.ctx.FloatSort(8, 24)
+	}
+	return zt.ctx.FloatSort(11, 53)
+}
+
+// shift транслирует сдвиг, счётчик которого может иметь другую ширину.
+// Оба операнда расширяются до общей ширины, чтобы счётчик не меньше ширины
+// левого операнда давал, как в Go, 0 (или -1 при арифметическом сдвиге отрицательного числа).
+func (zt *Z3Translator) shift(expr *symbolic.BinaryOperation, left, count z3.BV) z3.BV {
+	bits := expr.Left.Type().Bits()
+	countBits := expr.Right.Type().Bits()
+	width := max(bits, countBits)
+
+	signed := expr.Left.Type().IsSigned()
+	if bits < width {
+		if signed && expr.Operator == symbolic.SHR {
+			left = left.SignExtend(width - bits)
+		} else {
+			left = left.ZeroExtend(width - bits)
+		}
+	}
+	if countBits < width {
+		count = count.ZeroExtend(width - countBits)
+	}
+
+	var res z3.BV
+	switch {
+	case expr.Operator == symbolic.SHL:
+		res = left.Lsh(count)
+	case signed:
+		res = left.SRsh(count)
+	default:
+		res = left.URsh(count)
+	}
+
+	if bits < width {
+		res = res.Extract(bits-1, 0)
+	}
+	return res
+}
+
 // VisitLogicalOperation транслирует логическую операцию в Z3
 func (zt *Z3Translator) VisitLogicalOperation(expr *symbolic.LogicalOperation) interface{} {
 	operands := make([]z3.Bool, len(expr.Operands))
@@ -134,6 +316,90 @@ func (zt *Z3Translator) VisitLogicalOperation(expr *symbolic.LogicalOperation) i
 	panic("not implemented")
 }
 
+// VisitConditionalExpression транслирует условное выражение в Z3 ite
+func (zt *Z3Translator) VisitConditionalExpression(expr *symbolic.ConditionalExpression) interface{} {
+	cond := expr.Condition.Accept(zt).(z3.Bool)
+	if expr.Type().IsSequence() {
+		return zt.stringIte(cond, expr.Then.Accept(zt).(z3String), expr.Else.Accept(zt).(z3String))
+	}
+
+	then := expr.Then.Accept(zt).(z3.Value)
+	els := expr.Else.Accept(zt).(z3.Value)
+	return cond.IfThenElse(then, els)
+}
+
+// VisitArraySelect транслирует чтение элемента массива в Z3 select
+func (zt *Z3Translator) VisitArraySelect(expr *symbolic.ArraySelect) interface{} {
+	array := expr.Array.Accept(zt).(z3.Array)
+	return array.Select(expr.Index.Accept(zt).(z3.Value))
+}
+
+// VisitArrayStore транслирует запись в массив в Z3 store
+func (zt *Z3Translator) VisitArrayStore(expr *symbolic.ArrayStore) interface{} {
+	array := expr.Array.Accept(zt).(z3.Array)
+	return array.Store(expr.Index.Accept(zt).(z3.Value), expr.Value.Accept(zt).(z3.Value))
+}
+
+// VisitFunctionApplication транслирует применение неинтерпретируемой функции.
+// Функции с одинаковыми именем и сигнатурой транслируются в одно объявление Z3, что сохраняет
+// конгруэнтность, а одноимённые функции с разными сигнатурами - в разные перегруженные объявления.
+func (zt *Z3Translator) VisitFunctionApplication(expr *symbolic.FunctionApplication) interface{} {
+	key := expr.Function.String()
+	decl, ok := zt.funcs[key]
+	if !ok {
+		domain := make([]z3.Sort, len(expr.Function.Params))
+		for i, param := range expr.Function.Params {
+			domain[i] = zt.sort(param)
+		}
+		decl = zt.ctx.FuncDecl(expr.Function.Name, domain, zt.sort(expr.Function.Result))
+		zt.funcs[key] = decl
+	}
+
+	args := make([]z3.Value, len(expr.Args))
+	for i, arg := range expr.Args {
+		args[i] = arg.Accept(zt).(z3.Value)
+	}
+	return decl.Apply(args...)
+}
+
+// VisitConversion транслирует преобразование типа.
+// Целые числа расширяются знаком или нулём по знаковости исходного типа и усекаются через Extract.
+// Число с плавающей точкой сначала округляется к нулю, как при отбрасывании дробной части в Go.
+func (zt *Z3Translator) VisitConversion(expr *symbolic.Conversion) interface{} {
+	source, target := expr.Operand.Type(), expr.Target
+	value := expr.Operand.Accept(zt)
+	if source == target {
+		return value
+	}
+
+	switch {
+	case source.IsInteger() && target.IsInteger():
+		bv := value.(z3.BV)
+		switch {
+		case source.Bits() < target.Bits() && source.IsSigned():
+			return bv.SignExtend(target.Bits() - source.Bits())
+		case source.Bits() < target.Bits():
+			return bv.ZeroExtend(target.Bits() - source.Bits())
+		case source.Bits() > target.Bits():
+			return bv.Extract(target.Bits()-1, 0)
+		}
+		return bv
+	case source.IsInteger():
+		if source.IsSigned() {
+			return value.(z3.BV).SToFloat(zt.floatSort(target))
+		}
+		return value.(z3.BV).UToFloat(zt.floatSort(target))
+	case target.IsInteger():
+		truncated := value.(z3.Float).Round(z3.RoundToZero)
+		if target.IsSigned() {
+			return truncated.ToSBV(target.Bits())
+		}
+		return truncated.ToUBV(target.Bits())
+	default:
+		return value.(z3.Float).ToFloat(zt.floatSort(target))
+	}
+}
+
 func (zt *Z3Translator) VisitUnaryOperation(expr *symbolic.UnaryOperation) interface{} {
 	left := expr.Left.Accept(zt).(z3.BV)
 
@@ -144,30 +410,105 @@ func (zt *Z3Translator) VisitUnaryOperation(expr *symbolic.UnaryOperation) inter
 	panic("not implemented")
 }
 
+// ModelValue возвращает значение переменной в модели как значение Go для генерации
+// входных данных: int64 для знаковых целых, uint64 для беззнаковых, bool, float64, float32,
+// string или []byte. Переменные, не ограниченные моделью, получают значение по умолчанию.
+func (zt *Z3Translator) ModelValue(model *z3.Model, variable *symbolic.SymbolicVariable) (interface{}, error) {
+	if variable.ExprType.IsSequence() {
+		res, err := zt.stringValue(model, zt.stringVariable(variable.Name))
+		if err != nil {
+			return nil, fmt.Errorf("value of %s: %w", variable.Name, err)
+		}
+		if variable.ExprType == symbolic.BytesType {
+			return []byte(res), nil
+		}
+		return res, nil
+	}
+
+	value := model.Eval(zt.VisitVariable(variable).(z3.Value), true)
+	if value == nil {
+		return nil, fmt.Errorf("variable %s not found in model", variable.Name)
+	}
+
+	switch {
+	case variable.ExprType.IsInteger():
+		bv := value.(z3.BV)
+		if variable.ExprType.IsSigned() {
+			res, _, ok := bv.AsInt64()
+			if !ok {
+				return nil, fmt.Errorf("value of %s is not an integer literal: %s", variable.Name, value)
+			}
+			return res, nil
+		}
+		res, _, ok := bv.AsUint64()
+		if !ok {
+			return nil, fmt.Errorf("value of %s is not an integer literal: %s", variable.Name, value)
+		}
+		return res, nil
+	case variable.ExprType.IsFloat():
+		res, err := z3wrapper.FloatValue(value.(z3.Float))
+		if err != nil {
+			return nil, err
+		}
+		if variable.ExprType == symbolic.Float32Type {
+			return float32(res), nil
+		}
+		return res, nil
+	case variable.ExprType == symbolic.BoolType:
+		res, ok := value.(z3.Bool).AsBool()
+		if !ok {
+			return nil, fmt.Errorf("value of %s is not a boolean literal: %s", variable.Name, value)
+		}
+		return res, nil
+	}
+
+	return nil, fmt.Errorf("unsupported variable type %s", variable.ExprType)
+}
+
 // Вспомогательные методы
 
-// createZ3Variable создаёт Z3 переменную соответствующего типа
-func (zt *Z3Translator) createZ3Variable(name string, exprType symbolic.ExpressionType) z3.Value {
-	switch exprType {
-	case symbolic.IntType:
-		return zt.ctx.BVConst(name, 64)
-	case symbolic.BoolType:
-		return zt.ctx.BoolConst(name)
-	case symbolic.ArrayType:
-		return zt.ctx.ConstArray(zt.ctx.BVSort(64), zt.ctx.BVConst(name, 64))
+// createZ3Variable создаёт Z3 переменную соответствующего типа.
+// Массив становится настоящим символьным массивом: ни один его элемент не зафиксирован.
+func (zt *Z3Translator) createZ3Variable(expr *symbolic.SymbolicVariable) z3.Value {
+	if expr.ExprType == symbolic.ArrayType {
+		sort := zt.ctx.ArraySort(zt.sort(expr.Sort.Index), zt.sort(expr.Sort.Element))
+		return zt.ctx.Const(expr.Name, sort)
+	}
+	return zt.ctx.Const(expr.Name, zt.sort(expr.ExprType))
+}
+
+// sort возвращает сорт Z3 для скалярного типа выражения
+func (zt *Z3Translator) sort(exprType symbolic.ExpressionType) z3.Sort {
+	switch {
+	case exprType.IsInteger():
+		return zt.ctx.BVSort(exprType.Bits())
+	case exprType.IsFloat():
+		return zt.floatSort(exprType)
+	case exprType == symbolic.BoolType:
+		return zt.ctx.BoolSort()
 	}
 	panic("не реализовано")
 }
 
 // castToZ3Type приводит значение к нужному Z3 типу
 func (zt *Z3Translator) castToZ3Type(value interface{}, targetType symbolic.ExpressionType) (z3.Value, error) {
-	switch targetType {
-	case symbolic.IntType:
+	if targetType.IsInteger() {
 		v, ok := value.(z3.BV)
 		if !ok {
 			return nil, fmt.Errorf("incorrect type cast")
 		}
 		return v, nil
+	}
+
+	if targetType.IsFloat() {
+		v, ok := value.(z3.Float)
+		if !ok {
+			return nil, fmt.Errorf("incorrect type cast")
+		}
+		return v, nil
+	}
+
+	switch targetType {
 	case symbolic.BoolType:
 		v, ok := value.(z3.Bool)
 		if !ok {
@@ -0,0 +1,374 @@
+package translator
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"symbolic-execution-course/internal/symbolic"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+// assertValid проверяет, что условие истинно при любых значениях переменных
+func assertValid(t *testing.T, cond symbolic.SymbolicExpression) {
+	t.Helper()
+
+	zt := NewZ3Translator()
+	translated, err := zt.TranslateExpression(cond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	solver := z3.NewSolver(zt.ctx)
+	for _, assumption := range zt.Assumptions() {
+		solver.Assert(assumption)
+	}
+	solver.Assert(translated.(z3.Bool).Not())
+	sat, err := solver.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sat {
+		t.Errorf("%s does not hold: %s", cond, solver.Model())
+	}
+}
+
+func TestSizedIntegerSemantics(t *testing.T) {
+	c := symbolic.NewTypedIntConstant
+	op := symbolic.NewBinaryOperation
+	eq := func(left, right symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+		return op(left, right, symbolic.EQ)
+	}
+
+	tests := []struct {
+		name string
+		cond symbolic.SymbolicExpression
+	}{
+		{"uint8 wraparound", eq(op(c(200, symbolic.Uint8Type), c(100, symbolic.Uint8Type), symbolic.ADD), c(44, symbolic.Uint8Type))},
+		{"int8 overflow", eq(op(c(127, symbolic.Int8Type), c(1, symbolic.Int8Type), symbolic.ADD), c(-128, symbolic.Int8Type))},
+		{"int8 min / -1", eq(op(c(-128, symbolic.Int8Type), c(-1, symbolic.Int8Type), symbolic.DIV), c(-128, symbolic.Int8Type))},
+		{"signed remainder", eq(op(c(-7, symbolic.Int16Type), c(2, symbolic.Int16Type), symbolic.MOD), c(-1, symbolic.Int16Type))},
+		{"unsigned division", eq(op(c(-2, symbolic.Uint32Type), c(2, symbolic.Uint32Type), symbolic.DIV), c(0x7fffffff, symbolic.Uint32Type))},
+		{"unsigned comparison", op(c(1, symbolic.Uint64Type), c(-1, symbolic.Uint64Type), symbolic.LT)},
+		{"signed comparison", op(c(-1, symbolic.Int64Type), c(1, symbolic.Int64Type), symbolic.LT)},
+		{"shift beyond width", eq(op(c(1, symbolic.Uint8Type), c(8, symbolic.UintType), symbolic.SHL), c(0, symbolic.Uint8Type))},
+		{"wide shift count", eq(op(c(1, symbolic.Uint8Type), c(256+1, symbolic.Uint16Type), symbolic.SHL), c(0, symbolic.Uint8Type))},
+		{"arithmetic shift", eq(op(c(-16, symbolic.Int8Type), c(100, symbolic.Uint8Type), symbolic.SHR), c(-1, symbolic.Int8Type))},
+		{"logical shift", eq(op(c(0x80, symbolic.Uint8Type), c(7, symbolic.UintType), symbolic.SHR), c(1, symbolic.Uint8Type))},
+		{"bitwise", eq(op(c(0x0f, symbolic.ByteType), c(0x3c, symbolic.ByteType), symbolic.BXOR), c(0x33, symbolic.ByteType))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertValid(t, tt.cond)
+		})
+	}
+
+	// x + 1 > x не выполняется для uint8 при x = 255
+	x := symbolic.NewSymbolicVariable("x", symbolic.Uint8Type)
+	zt := NewZ3Translator()
+	translated, _ := zt.TranslateExpression(op(op(x, c(1, symbolic.Uint8Type), symbolic.ADD), x, symbolic.LE))
+	solver := z3.NewSolver(zt.ctx)
+	solver.Assert(translated.(z3.Bool))
+	if sat, err := solver.Check(); err != nil || !sat {
+		t.Errorf("expected x + 1 <= x to be satisfiable for uint8: %v", err)
+	}
+}
+
+func TestFloatSemantics(t *testing.T) {
+	op := symbolic.NewBinaryOperation
+	f64 := symbolic.NewFloatConstant
+	f32 := func(v float64) symbolic.SymbolicExpression {
+		return symbolic.NewTypedFloatConstant(v, symbolic.Float32Type)
+	}
+	nan := f64(math.NaN())
+
+	assertValid(t, op(op(f64(0.1), f64(0.2), symbolic.ADD), f64(0.30000000000000004), symbolic.EQ))
+	assertValid(t, op(op(f32(0.1), f32(0.2), symbolic.ADD), f32(0.3), symbolic.EQ))
+	assertValid(t, op(nan, nan, symbolic.NE))
+	assertValid(t, symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{op(nan, f64(1), symbolic.LT)}, symbolic.NOT))
+	assertValid(t, op(op(f64(1), f64(0), symbolic.DIV), f64(math.Inf(1)), symbolic.EQ))
+
+	// b + (a + 1.1) > 10.1 из testSimpleSum: модель должна удовлетворять условию в Go
+	a := symbolic.NewSymbolicVariable("a", symbolic.FloatType)
+	b := symbolic.NewSymbolicVariable("b", symbolic.Float32Type)
+	c := symbolic.NewSymbolicVariable("c", symbolic.FloatType)
+	cond := symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		op(op(c, op(a, f64(1.1), symbolic.ADD), symbolic.ADD), f64(10.1), symbolic.GT),
+		op(b, f32(0.5), symbolic.GT),
+	}, symbolic.AND)
+
+	zt := NewZ3Translator()
+	translated, err := zt.TranslateExpression(cond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	solver := z3.NewSolver(zt.ctx)
+	solver.Assert(translated.(z3.Bool))
+	if sat, err := solver.Check(); err != nil || !sat {
+		t.Fatalf("expected condition to be satisfiable: %v", err)
+	}
+
+	model := solver.Model()
+	values := map[string]interface{}{}
+	for _, v := range []*symbolic.SymbolicVariable{a, b, c} {
+		value, err := zt.ModelValue(model, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values[v.Name] = value
+	}
+
+	av, aok := values["a"].(float64)
+	bv, bok := values["b"].(float32)
+	cv, cok := values["c"].(float64)
+	if !aok || !bok || !cok {
+		t.Fatalf("unexpected model value types: %#v", values)
+	}
+	if !(cv+(av+1.1) > 10.1) || !(bv > 0.5) {
+		t.Errorf("model a=%v b=%v c=%v does not satisfy %s", av, bv, cv, cond)
+	}
+}
+
+func TestConditionalExpression(t *testing.T) {
+	x := symbolic.NewSymbolicVariable("x", symbolic.Int32Type)
+	zero := symbolic.NewTypedIntConstant(0, symbolic.Int32Type)
+	minusOne := symbolic.NewTypedIntConstant(-1, symbolic.Int32Type)
+
+	// abs(x) >= 0 нарушается только для math.MinInt32
+	abs := symbolic.Ite(
+		symbolic.NewBinaryOperation(x, zero, symbolic.LT),
+		symbolic.NewBinaryOperation(x, minusOne, symbolic.MUL),
+		x,
+	)
+	assertValid(t, symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(x, symbolic.NewTypedIntConstant(math.MinInt32, symbolic.Int32Type), symbolic.EQ),
+		symbolic.NewBinaryOperation(abs, zero, symbolic.GE),
+	}, symbolic.OR))
+
+	flag := symbolic.NewSymbolicVariable("flag", symbolic.BoolType)
+	assertValid(t, symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		flag,
+		symbolic.NewConditionalExpression(flag, symbolic.NewBoolConstant(false), symbolic.NewBoolConstant(true)),
+	}, symbolic.OR))
+}
+
+func TestArraySelectStore(t *testing.T) {
+	sort := symbolic.ArraySort{Index: symbolic.IntType, Element: symbolic.Uint8Type}
+	a := symbolic.NewArrayVariable("a", sort)
+	i := symbolic.NewSymbolicVariable("i", symbolic.IntType)
+	j := symbolic.NewSymbolicVariable("j", symbolic.IntType)
+	v := symbolic.NewSymbolicVariable("v", symbolic.Uint8Type)
+
+	stored := symbolic.NewArrayStore(a, i, v)
+	eq := func(l, r symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+		return symbolic.NewBinaryOperation(l, r, symbolic.EQ)
+	}
+	implies := func(l, r symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+		return symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{l, r}, symbolic.IMPLIES)
+	}
+
+	if sel := symbolic.NewArraySelect(stored, j); sel.Type() != symbolic.Uint8Type || sel.String() != "a[i := v][j]" {
+		t.Errorf("unexpected select %s of type %s", sel, sel.Type())
+	}
+
+	// Чтение только что записанного индекса возвращает записанное значение
+	assertValid(t, eq(symbolic.NewArraySelect(stored, i), v))
+	// Запись не затрагивает другие индексы
+	assertValid(t, implies(
+		symbolic.NewBinaryOperation(i, j, symbolic.NE),
+		eq(symbolic.NewArraySelect(stored, j), symbolic.NewArraySelect(a, j)),
+	))
+	// Разные значения по символьным индексам возможны только при разных индексах
+	assertValid(t, implies(
+		symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+			eq(symbolic.NewArraySelect(a, i), symbolic.NewTypedIntConstant(1, symbolic.Uint8Type)),
+			eq(symbolic.NewArraySelect(a, j), symbolic.NewTypedIntConstant(2, symbolic.Uint8Type)),
+		}, symbolic.AND),
+		symbolic.NewBinaryOperation(i, j, symbolic.NE),
+	))
+}
+
+func TestFunctionApplication(t *testing.T) {
+	hash := symbolic.NewFunctionDeclaration("hash", symbolic.Uint32Type, symbolic.IntType, symbolic.BoolType)
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	y := symbolic.NewSymbolicVariable("y", symbolic.IntType)
+	flag := symbolic.NewSymbolicVariable("flag", symbolic.BoolType)
+
+	hx := symbolic.NewFunctionApplication(hash, x, flag)
+	hy := symbolic.NewFunctionApplication(hash, y, flag)
+	if hx.String() != "hash(x, flag)" || hx.Type() != symbolic.Uint32Type {
+		t.Errorf("unexpected application %s of type %s", hx, hx.Type())
+	}
+
+	// Конгруэнтность: равные аргументы дают равные результаты
+	assertValid(t, symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(x, y, symbolic.EQ),
+		symbolic.NewBinaryOperation(hx, hy, symbolic.EQ),
+	}, symbolic.IMPLIES))
+
+	// Но разные аргументы могут дать равные результаты: функция ничем не ограничена
+	zt := NewZ3Translator()
+	translated, _ := zt.TranslateExpression(symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(x, y, symbolic.NE),
+		symbolic.NewBinaryOperation(hx, hy, symbolic.EQ),
+	}, symbolic.AND))
+	solver := z3.NewSolver(zt.ctx)
+	solver.Assert(translated.(z3.Bool))
+	if sat, err := solver.Check(); err != nil || !sat {
+		t.Errorf("expected collision to be satisfiable: %v", err)
+	}
+
+	// Одноимённая функция с другой сигнатурой получает отдельное объявление
+	narrow := symbolic.NewFunctionDeclaration("hash", symbolic.Uint8Type, symbolic.Int8Type)
+	b := symbolic.NewSymbolicVariable("b", symbolic.Int8Type)
+	assertValid(t, symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(hx, hx, symbolic.EQ),
+		symbolic.NewBinaryOperation(symbolic.NewFunctionApplication(narrow, b), symbolic.NewFunctionApplication(narrow, b), symbolic.EQ),
+	}, symbolic.AND))
+}
+
+func TestStringSemantics(t *testing.T) {
+	str := func(v string) symbolic.SymbolicExpression { return symbolic.NewStringConstant(v) }
+	num := symbolic.NewIntConstant
+	op := symbolic.NewBinaryOperation
+	sop := symbolic.NewStringOperation
+	eq := func(left, right symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+		return op(left, right, symbolic.EQ)
+	}
+	s := symbolic.NewSymbolicVariable("s", symbolic.StringType)
+	u := symbolic.NewSymbolicVariable("u", symbolic.StringType)
+
+	tests := []struct {
+		name string
+		cond symbolic.SymbolicExpression
+	}{
+		{"concat", eq(op(str("ab"), str("c"), symbolic.ADD), str("abc"))},
+		{"len of concat", eq(sop(symbolic.LEN, op(s, u, symbolic.ADD)), op(sop(symbolic.LEN, s), sop(symbolic.LEN, u), symbolic.ADD))},
+		{"at", eq(sop(symbolic.AT, str("abc"), num(1)), symbolic.NewTypedIntConstant('b', symbolic.ByteType))},
+		{"slice", eq(sop(symbolic.SLICE, str("hello"), num(1), num(3)), str("el"))},
+		{"not equal", op(str("ab"), str("abc"), symbolic.NE)},
+		{"prefix order", op(str("ab"), str("abc"), symbolic.LT)},
+		{"byte order", op(str("b"), str("abc"), symbolic.GT)},
+		{"reflexive order", op(s, s, symbolic.LE)},
+		{"has prefix", sop(symbolic.HAS_PREFIX, op(str("http"), s, symbolic.ADD), str("ht"))},
+		{"has suffix", sop(symbolic.HAS_SUFFIX, op(s, str(".go"), symbolic.ADD), str("go"))},
+		{"contains", sop(symbolic.CONTAINS, op(s, op(str("x=1"), u, symbolic.ADD), symbolic.ADD), str("="))},
+		{"index", eq(sop(symbolic.INDEX, str("a=b=c"), str("=")), num(1))},
+		{"index not found", eq(sop(symbolic.INDEX, str("abc"), str("d")), num(-1))},
+		{"bytes round-trip", eq(sop(symbolic.FROM_BYTES, sop(symbolic.TO_BYTES, s)), s)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertValid(t, tt.cond)
+		})
+	}
+
+	// s + "!" == "hi!" имеет единственное решение s = "hi"
+	zt := NewZ3Translator()
+	translated, _ := zt.TranslateExpression(eq(op(s, str("!"), symbolic.ADD), str("hi!")))
+	solver := z3.NewSolver(zt.ctx)
+	for _, assumption := range zt.Assumptions() {
+		solver.Assert(assumption)
+	}
+	solver.Assert(translated.(z3.Bool))
+	if sat, err := solver.Check(); err != nil || !sat {
+		t.Fatalf("expected s + \"!\" == \"hi!\" to be satisfiable: %v", err)
+	}
+	value, err := zt.ModelValue(solver.Model(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "hi" {
+		t.Errorf("s = %q, want \"hi\"", value)
+	}
+}
+
+func TestConversionSemantics(t *testing.T) {
+	c := symbolic.NewTypedIntConstant
+	conv := symbolic.NewConversion
+	eq := func(left, right symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+		return symbolic.NewBinaryOperation(left, right, symbolic.EQ)
+	}
+	f64 := symbolic.NewFloatConstant
+
+	tests := []struct {
+		name string
+		cond symbolic.SymbolicExpression
+	}{
+		{"sign extension", eq(conv(c(-1, symbolic.Int8Type), symbolic.IntType), c(-1, symbolic.IntType))},
+		{"zero extension", eq(conv(c(0xff, symbolic.Uint8Type), symbolic.IntType), c(255, symbolic.IntType))},
+		{"truncation", eq(conv(c(0x1ff, symbolic.IntType), symbolic.ByteType), c(0xff, symbolic.ByteType))},
+		{"signed truncation", eq(conv(c(0x80, symbolic.IntType), symbolic.Int8Type), c(-128, symbolic.Int8Type))},
+		{"same width", eq(conv(c(-1, symbolic.Int32Type), symbolic.Uint32Type), c(0xffffffff, symbolic.Uint32Type))},
+		{"int to float", eq(conv(c(-3, symbolic.IntType), symbolic.FloatType), f64(-3))},
+		{"uint to float", eq(conv(c(-1, symbolic.Uint8Type), symbolic.FloatType), f64(255))},
+		{"float truncation", eq(conv(f64(2.9), symbolic.IntType), c(2, symbolic.IntType))},
+		{"negative float truncation", eq(conv(f64(-2.9), symbolic.Int16Type), c(-2, symbolic.Int16Type))},
+		{"float to uint", eq(conv(f64(200.5), symbolic.Uint8Type), c(200, symbolic.Uint8Type))},
+		{"float64 to float32", eq(conv(f64(0.1), symbolic.Float32Type), symbolic.NewTypedFloatConstant(float64(float32(0.1)), symbolic.Float32Type))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertValid(t, tt.cond)
+		})
+	}
+
+	// int32(x) + 1 < 0 выполнимо для положительного x, например x = math.MaxInt32
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	cond := symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(x, c(0, symbolic.IntType), symbolic.GT),
+		symbolic.NewBinaryOperation(
+			symbolic.NewBinaryOperation(conv(x, symbolic.Int32Type), c(1, symbolic.Int32Type), symbolic.ADD),
+			c(0, symbolic.Int32Type), symbolic.LT),
+	}, symbolic.AND)
+	zt := NewZ3Translator()
+	translated, _ := zt.TranslateExpression(cond)
+	solver := z3.NewSolver(zt.ctx)
+	solver.Assert(translated.(z3.Bool))
+	if sat, err := solver.Check(); err != nil || !sat {
+		t.Fatalf("expected %s to be satisfiable: %v", cond, err)
+	}
+	value, err := zt.ModelValue(solver.Model(), x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := value.(int64); int32(v)+1 >= 0 {
+		t.Errorf("x = %d does not overflow int32", v)
+	}
+}
+
+func TestTranslationErrors(t *testing.T) {
+	b := symbolic.NewSymbolicVariable("b", symbolic.BoolType)
+	f := symbolic.NewSymbolicVariable("f", symbolic.FloatType)
+
+	tests := []struct {
+		name string
+		expr symbolic.SymbolicExpression
+	}{
+		{"bool addition", &symbolic.BinaryOperation{Left: b, Right: symbolic.NewBoolConstant(true), Operator: symbolic.ADD}},
+		{"bool ordering", &symbolic.BinaryOperation{Left: b, Right: b, Operator: symbolic.LT}},
+		{"string product", &symbolic.BinaryOperation{
+			Left: symbolic.NewStringConstant("a"), Right: symbolic.NewStringConstant("b"), Operator: symbolic.MUL,
+		}},
+		{"float remainder", &symbolic.BinaryOperation{Left: f, Right: symbolic.NewFloatConstant(2), Operator: symbolic.MOD}},
+		{"float shift", &symbolic.BinaryOperation{Left: f, Right: symbolic.NewIntConstant(1), Operator: symbolic.SHL}},
+		{"reference", &symbolic.Ref{Tpe: symbolic.ObjectType, Ptr: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewZ3Translator().TranslateExpression(tt.expr)
+			var te *TranslationError
+			if !errors.As(err, &te) {
+				t.Errorf("expected a translation error for %s, got %v", tt.expr, err)
+			}
+		})
+	}
+
+	// Сравнение булевых значений на равенство поддерживается
+	assertValid(t, symbolic.NewBinaryOperation(b, b, symbolic.EQ))
+}
@@ -0,0 +1,40 @@
+package z3wrapper
+
+/*
+#cgo LDFLAGS: -lz3
+#include <z3.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// RunSMTLib2 выполняет скрипт SMT-LIB2 в отдельном контексте Z3 и возвращает
+// ответы решателя, например "sat" для скрипта с одной командой check-sat.
+// Ошибки разбора и выполнения команд ("(error ...)" в ответе) возвращаются как error.
+func RunSMTLib2(script string) (string, error) {
+	config := C.Z3_mk_config()
+	ctx := C.Z3_mk_context(config)
+	C.Z3_del_config(config)
+	defer C.Z3_del_context(ctx)
+	// Без обработчика ошибка Z3 не завершает процесс, а сохраняется в контексте
+	C.Z3_set_error_handler(ctx, nil)
+
+	cscript := C.CString(script)
+	defer C.free(unsafe.Pointer(cscript))
+	output := strings.TrimSpace(C.GoString(C.Z3_eval_smtlib2_string(ctx, cscript)))
+
+	if code := C.Z3_get_error_code(ctx); code != C.Z3_OK {
+		return output, fmt.Errorf("z3: %s", C.GoString(C.Z3_get_error_msg(ctx, code)))
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "(error") {
+			return output, fmt.Errorf("z3: %s", line)
+		}
+	}
+	return output, nil
+}
@@ -5,6 +5,7 @@ package z3wrapper
 import (
 	"fmt"
 	"github.com/ebukreev/go-z3/z3"
+	"math"
 	"strconv"
 )
 
@@ -118,3 +119,39 @@ func (s *Solver) GetBoolValue(model *z3.Model, variable z3.Bool) (bool, error) {
 		return false, fmt.Errorf("unexpected boolean value: %s", str)
 	}
 }
+
+// CreateFloat64Var создаёт переменную с плавающей точкой двойной точности
+func (s *Solver) CreateFloat64Var(name string) z3.Float {
+	return s.ctx.Const(name, s.ctx.FloatSort(11, 53)).(z3.Float)
+}
+
+// CreateFloat64Lit создаёт константу с плавающей точкой двойной точности
+func (s *Solver) CreateFloat64Lit(value float64) z3.Float {
+	return s.ctx.FromFloat64(value, s.ctx.FloatSort(11, 53))
+}
+
+// GetFloatValue получает значение переменной с плавающей точкой из модели.
+// NaN и бесконечности возвращаются как соответствующие значения float64.
+func (s *Solver) GetFloatValue(model *z3.Model, variable z3.Float) (float64, error) {
+	value := model.Eval(variable, true)
+	if value == nil {
+		return 0, fmt.Errorf("variable not found in model")
+	}
+
+	return FloatValue(value.(z3.Float))
+}
+
+// FloatValue преобразует литерал Z3 с плавающей точкой в float64.
+// Значения float32 представимы в float64 точно.
+func FloatValue(value z3.Float) (float64, error) {
+	f, isLiteral := value.AsBigFloat()
+	if !isLiteral {
+		return 0, fmt.Errorf("value %s is not a floating-point literal", value)
+	}
+	if f == nil {
+		return math.NaN(), nil
+	}
+
+	res, _ := f.Float64()
+	return res, nil
+}
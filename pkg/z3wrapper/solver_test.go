@@ -1,6 +1,7 @@
 package z3wrapper
 
 import (
+	"math"
 	"testing"
 )
 
@@ -148,3 +149,65 @@ func TestBooleanOperations(t *testing.T) {
 		t.Errorf("Expected b = false, got %v", bVal)
 	}
 }
+
+func TestFloatValues(t *testing.T) {
+	solver := NewSolver()
+	defer solver.Close()
+
+	x := solver.CreateFloat64Var("x")
+
+	// В float64 0.2 + 0.1 != 0.3, поэтому решатель должен учесть округление
+	solver.Assert(x.Add(solver.CreateFloat64Lit(0.1)).IEEEEq(solver.CreateFloat64Lit(0.3)))
+
+	sat, err := solver.IsSatisfiable()
+	if err != nil {
+		t.Fatalf("Error checking satisfiability: %v", err)
+	}
+	if !sat {
+		t.Fatal("Expected x + 0.1 == 0.3 to be satisfiable")
+	}
+
+	xVal, err := solver.GetFloatValue(solver.Model(), x)
+	if err != nil {
+		t.Fatalf("Error getting x value: %v", err)
+	}
+	if xVal+0.1 != 0.3 {
+		t.Errorf("Model value %v does not satisfy x + 0.1 == 0.3", xVal)
+	}
+
+	// NaN не равен самому себе
+	y := solver.CreateFloat64Var("y")
+	solver.Assert(y.IEEEEq(y).Not())
+
+	z := solver.CreateFloat64Var("z")
+	solver.Assert(z.IsInfinite())
+	solver.Assert(z.IsNegative())
+
+	if sat, _ := solver.IsSatisfiable(); !sat {
+		t.Fatal("Expected y != y and z = -Inf to be satisfiable")
+	}
+	model := solver.Model()
+
+	yVal, err := solver.GetFloatValue(model, y)
+	if err != nil || !math.IsNaN(yVal) {
+		t.Errorf("Expected NaN, got %v (%v)", yVal, err)
+	}
+	zVal, err := solver.GetFloatValue(model, z)
+	if err != nil || !math.IsInf(zVal, -1) {
+		t.Errorf("Expected -Inf, got %v (%v)", zVal, err)
+	}
+}
+
+func TestRunSMTLib2(t *testing.T) {
+	output, err := RunSMTLib2("(declare-fun x () Int)\n(assert (> x 1))\n(assert (< x 2))\n(check-sat)")
+	if err != nil {
+		t.Fatalf("Error running script: %v", err)
+	}
+	if output != "unsat" {
+		t.Errorf("Expected unsat, got %q", output)
+	}
+
+	if _, err := RunSMTLib2("(assert (> y 1))\n(check-sat)"); err == nil {
+		t.Error("Expected an error for an undeclared symbol")
+	}
+}